@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// appendLogLine appends the line of --append-to for a completed timer, e.g.
+// "2024-01-15 14:30 [work] 25m – finished". The file is locked while writing
+// so concurrent toki processes don't interleave their lines.
+func appendLogLine(path, name string, total time.Duration, finishedAt time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	line := finishedAt.Format("2006-01-02 15:04") + " "
+	if name != "" {
+		line += "[" + name + "] "
+	}
+	line += formatPresetDuration(total.Round(time.Second)) + " – finished\n"
+	_, err = fmt.Fprint(f, line)
+	if unlockErr := unlockFile(f); err == nil {
+		err = unlockErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
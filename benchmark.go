@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/colorprofile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchDuration time.Duration
+	benchInterval time.Duration
+	benchJSON     bool
+)
+
+// benchResult summarizes the intervals measured between ticks.
+type benchResult struct {
+	Ticks       int           `json:"ticks"`
+	IntervalNS  int64         `json:"interval_ns"`
+	MeanNS      int64         `json:"mean_ns"`
+	P95NS       int64         `json:"p95_ns"`
+	MaxJitterNS int64         `json:"max_jitter_ns"`
+	Histogram   []benchBucket `json:"histogram"`
+}
+
+// benchBucket counts the measured intervals up to MaxNS.
+type benchBucket struct {
+	MaxNS int64 `json:"max_ns"`
+	Count int   `json:"count"`
+}
+
+// measureTicks ticks every interval for d the same way the timer does, by
+// scheduling each tick after the previous one, and returns the time between
+// consecutive ticks.
+func measureTicks(d, interval time.Duration) []time.Duration {
+	var intervals []time.Duration
+	last := time.Now()
+	for end := last.Add(d); last.Before(end); {
+		now := <-time.After(interval)
+		intervals = append(intervals, now.Sub(last))
+		last = now
+	}
+	return intervals
+}
+
+func summarizeTicks(intervals []time.Duration, interval time.Duration) benchResult {
+	sorted := append([]time.Duration(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total, jitter time.Duration
+	for _, d := range sorted {
+		total += d
+		jitter = max(jitter, (d - interval).Abs())
+	}
+	res := benchResult{
+		Ticks:       len(sorted),
+		IntervalNS:  int64(interval),
+		MeanNS:      int64(total / time.Duration(len(sorted))),
+		P95NS:       int64(sorted[(len(sorted)*95-1)/100]),
+		MaxJitterNS: int64(jitter),
+	}
+
+	const buckets = 10
+	lo, hi := sorted[0], sorted[len(sorted)-1]
+	step := max((hi-lo)/buckets, 1)
+	for i := range buckets {
+		res.Histogram = append(res.Histogram, benchBucket{MaxNS: int64(lo + step*time.Duration(i+1))})
+	}
+	res.Histogram[buckets-1].MaxNS = int64(hi)
+	for _, d := range sorted {
+		i := min(int((d-lo)/step), buckets-1)
+		res.Histogram[i].Count++
+	}
+	return res
+}
+
+var benchmarkCmd = &cobra.Command{
+	Use:          "benchmark",
+	Short:        "Measures the tick accuracy of this system",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		interval := benchInterval
+		if interval == 0 {
+			interval = timerInterval(benchDuration)
+		}
+		if interval < time.Millisecond || interval > benchDuration {
+			return fmt.Errorf("--interval must be between 1ms and the duration %s", benchDuration)
+		}
+
+		res := summarizeTicks(measureTicks(benchDuration, interval), interval)
+		out := cmd.OutOrStdout()
+		if benchJSON {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(res)
+		}
+
+		out = colorprofile.NewWriter(out, os.Environ())
+		fmt.Fprintf(out, "%s %d every %s\n", boldStyle.Render("Ticks:"), res.Ticks, interval)
+		fmt.Fprintf(out, "%s %s\n", boldStyle.Render("Mean interval:"), time.Duration(res.MeanNS))
+		fmt.Fprintf(out, "%s %s\n", boldStyle.Render("p95 interval:"), time.Duration(res.P95NS))
+		fmt.Fprintf(out, "%s %s\n", boldStyle.Render("Max jitter:"), time.Duration(res.MaxJitterNS))
+		fmt.Fprintln(out, boldStyle.Render("Histogram:"))
+		peak := 0
+		for _, b := range res.Histogram {
+			peak = max(peak, b.Count)
+		}
+		for _, b := range res.Histogram {
+			bar := strings.Repeat("█", b.Count*40/peak)
+			fmt.Fprintf(out, "  ≤ %-12s %s %d\n", time.Duration(b.MaxNS), bar, b.Count)
+		}
+		return nil
+	},
+}
+
+func init() {
+	benchmarkCmd.Flags().DurationVarP(&benchDuration, "duration", "", 10*time.Second, "how long to measure")
+	benchmarkCmd.Flags().DurationVarP(&benchInterval, "interval", "", 0, "tick rate to measure (default as chosen for the duration)")
+	benchmarkCmd.Flags().BoolVarP(&benchJSON, "json", "", false, "print the statistics as JSON")
+	rootCmd.AddCommand(benchmarkCmd)
+}
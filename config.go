@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "toki", "config.toml")
+}
+
+// configPath is the config file loaded on startup.
+var configPath string
+
+// configSection decodes the named table of the config file into v. It is a
+// no-op when the file or the table does not exist.
+func configSection(name string, v any) error {
+	var file map[string]toml.Primitive
+	md, err := toml.DecodeFile(configPath, &file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if section, ok := file[name]; ok {
+		return md.PrimitiveDecode(section, v)
+	}
+	return nil
+}
+
+// loadConfig applies the top level keys of the config file as defaults for
+// the root command flags of the same name. It must run before the flags are
+// parsed so that flags given on the command line still take precedence.
+func loadConfig(path string) error {
+	configPath = path
+	var values map[string]any
+	if _, err := toml.DecodeFile(path, &values); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for key, value := range values {
+		flag := rootCmd.Flags().Lookup(key)
+		if flag == nil {
+			flag = rootCmd.PersistentFlags().Lookup(key)
+		}
+		table, isTable := value.(map[string]any)
+		switch {
+		case isTable && flag == nil:
+			continue // sections are read by the features they configure
+		case flag == nil:
+			return fmt.Errorf("%s: unknown option %q", path, key)
+		case isTable && flag.Value.Type() != "stringToString":
+			return fmt.Errorf("%s: invalid value for %q: a table is only allowed for key=value options", path, key)
+		case isTable:
+			// Keys given on the command line are added to these, replacing
+			// the ones of the same name.
+			for _, k := range slices.Sorted(maps.Keys(table)) {
+				if err := flag.Value.Set(k + "=" + fmt.Sprint(table[k])); err != nil {
+					return fmt.Errorf("%s: invalid value for %q: %w", path, key, err)
+				}
+			}
+			flag.DefValue = flag.Value.String()
+			continue
+		}
+
+		items, ok := value.([]any)
+		if !ok {
+			items = []any{value}
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			// Replace, unlike Set, leaves the flag unchanged so that
+			// values given on the command line replace these instead
+			// of adding to them.
+			values := make([]string, len(items))
+			for i, item := range items {
+				values[i] = fmt.Sprint(item)
+			}
+			if err := slice.Replace(values); err != nil {
+				return fmt.Errorf("%s: invalid value for %q: %w", path, key, err)
+			}
+		} else {
+			for _, item := range items {
+				if err := flag.Value.Set(fmt.Sprint(item)); err != nil {
+					return fmt.Errorf("%s: invalid value for %q: %w", path, key, err)
+				}
+			}
+		}
+		flag.DefValue = flag.Value.String()
+	}
+	return nil
+}
+
+// defaultConfig renders every root command flag as a commented out TOML
+// assignment of its default value.
+func defaultConfig() string {
+	var b strings.Builder
+	b.WriteString("# toki configuration, values here are used as flag defaults.\n")
+	flags := pflag.NewFlagSet("toki", pflag.ContinueOnError)
+	flags.AddFlagSet(rootCmd.Flags())
+	flags.AddFlagSet(rootCmd.PersistentFlags())
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Name == "help" || f.Name == "version" {
+			return
+		}
+		value := f.DefValue
+		switch f.Value.Type() {
+		case "bool", "int", "float64":
+		case "stringArray":
+			items := f.Value.(pflag.SliceValue).GetSlice()
+			for i, item := range items {
+				items[i] = strconv.Quote(item)
+			}
+			value = "[" + strings.Join(items, ", ") + "]"
+		case "stringToString":
+			value = "{}"
+		default:
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&b, "\n# %s\n# %s = %s\n", f.Usage, f.Name, value)
+	})
+	b.WriteString("\n# progress percentages at which the bar turns yellow and red\n")
+	b.WriteString("# [urgency]\n# warning = 75\n# critical = 90\n")
+	b.WriteString("\n# keys of the quit, interrupt, pause, skip and restart actions\n")
+	b.WriteString("# [keybindings]\n# quit = [\"esc\", \"q\"]\n# interrupt = [\"ctrl+c\"]\n# pause = [\"space\", \"p\"]\n")
+	b.WriteString("# skip = [\"n\", \"right\"]\n# restart = [\"r\"]\n")
+	b.WriteString("\n# named durations to use as the duration argument, like TOKI_PRESETS\n")
+	b.WriteString("# [presets]\n# pomo = \"25m,5m\"\n")
+	return b.String()
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manages the configuration file",
+	Args:  cobra.NoArgs,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:          "edit",
+	Short:        "Opens the configuration file in $EDITOR",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		path := defaultConfigPath()
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(defaultConfig()), 0o644); err != nil {
+				return err
+			}
+		}
+
+		editor := os.Getenv("EDITOR")
+		if editor == "" {
+			editor = "vi"
+		}
+		c := exec.Command("sh", "-c", editor+" "+strconv.Quote(path))
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		return c.Run()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configEditCmd)
+	rootCmd.AddCommand(configCmd)
+}
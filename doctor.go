@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/spf13/cobra"
+)
+
+// checkStatus is the outcome of a toki doctor check. Only failures make the
+// command exit with an error, warnings are about optional features.
+type checkStatus int
+
+const (
+	checkPass checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+var checkStyles = map[checkStatus]lipgloss.Style{
+	checkPass: lipgloss.NewStyle().Foreground(lipgloss.Color("2")).SetString("pass"),
+	checkWarn: lipgloss.NewStyle().Foreground(lipgloss.Color("3")).SetString("warn"),
+	checkFail: lipgloss.NewStyle().Foreground(lipgloss.Color("1")).SetString("fail"),
+}
+
+// check is one row of the toki doctor table.
+type check struct {
+	name   string
+	status checkStatus
+	detail string
+}
+
+// checkWritable reports whether files can be created in dir, creating it if
+// needed like toki does before writing.
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".toki-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkCommands returns the check of an optional feature, passing when one of
+// the commands it needs is found in $PATH.
+func checkCommands(name, feature string, commands ...string) check {
+	if len(commands) == 0 {
+		return check{name, checkWarn, fmt.Sprintf("%s is not supported on %s", feature, runtime.GOOS)}
+	}
+	for _, c := range commands {
+		if path, err := exec.LookPath(c); err == nil {
+			return check{name, checkPass, path}
+		}
+	}
+	return check{name, checkWarn, fmt.Sprintf("%s not found in $PATH, needed for %s", strings.Join(commands, " or "), feature)}
+}
+
+func runChecks() []check {
+	var checks []check
+
+	switch profile := colorprofile.Env(os.Environ()); {
+	case noColor():
+		checks = append(checks, check{"colors", checkWarn, "disabled by NO_COLOR"})
+	case profile <= colorprofile.Ascii:
+		checks = append(checks, check{"colors", checkWarn, "the terminal does not support colors"})
+	default:
+		checks = append(checks, check{"colors", checkPass, profile.String()})
+	}
+
+	if term := os.Getenv("TERM"); term != "" {
+		checks = append(checks, check{"$TERM", checkPass, term})
+	} else {
+		checks = append(checks, check{"$TERM", checkWarn, "not set"})
+	}
+
+	var values map[string]any
+	switch _, err := toml.DecodeFile(configPath, &values); {
+	case errors.Is(err, os.ErrNotExist):
+		checks = append(checks, check{"config", checkPass, configPath + " does not exist, using defaults"})
+	case err != nil:
+		checks = append(checks, check{"config", checkFail, err.Error()})
+	default:
+		checks = append(checks, check{"config", checkPass, configPath})
+	}
+
+	if logPath == "" {
+		checks = append(checks, check{"history", checkPass, "disabled"})
+	} else if err := checkWritable(filepath.Dir(logPath)); err != nil {
+		checks = append(checks, check{"history", checkFail, err.Error()})
+	} else if f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		checks = append(checks, check{"history", checkFail, err.Error()})
+	} else {
+		if f != nil {
+			f.Close()
+		}
+		checks = append(checks, check{"history", checkPass, logPath})
+	}
+
+	if err := checkWritable(instanceDir()); err != nil {
+		checks = append(checks, check{"state", checkFail, err.Error()})
+	} else {
+		checks = append(checks, check{"state", checkPass, instanceDir()})
+	}
+
+	var notifier []string
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		notifier = []string{"notify-send"}
+	case "darwin":
+		notifier = []string{"osascript"}
+	case "windows":
+		notifier = []string{"toast"}
+	}
+	checks = append(checks, checkCommands("notifications", "--notify", notifier...))
+	checks = append(checks, checkCommands("sound", "--sound", soundPlayers[runtime.GOOS]...))
+	return checks
+}
+
+var doctorCmd = &cobra.Command{
+	Use:          "doctor",
+	Short:        "Checks the environment for problems with toki",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checks := runChecks()
+		rows := make([][]string, len(checks))
+		failed := 0
+		for i, c := range checks {
+			rows[i] = []string{checkStyles[c.status].String(), c.name, c.detail}
+			if c.status == checkFail {
+				failed++
+			}
+		}
+		if err := writeTable(colorprofile.NewWriter(cmd.OutOrStdout(), os.Environ()), rows); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return &ExitError{Code: 1, Err: fmt.Errorf("%d of %d checks failed", failed, len(checks))}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/spf13/cobra"
+)
+
+// editSegment is a segment of the sequence built by toki edit.
+type editSegment struct {
+	duration string
+	name     string
+}
+
+// editField is the value being typed into the editor's input.
+type editField int
+
+const (
+	editNone editField = iota
+	editDuration
+	editName
+	editHook
+)
+
+// editAction is what toki edit does once the editor is closed.
+type editAction int
+
+const (
+	editQuit editAction = iota
+	editRun
+	editPrint
+)
+
+// editModel is the interactive editor of toki edit.
+type editModel struct {
+	segments []editSegment
+	cursor   int
+	hook     string
+	field    editField
+	input    textinput.Model
+	err      string
+	action   editAction
+}
+
+func newEditModel() editModel {
+	input := textinput.New()
+	input.Prompt = ""
+	return editModel{input: input}
+}
+
+func (m editModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m editModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.field != editNone {
+		return m.updateInput(msg)
+	}
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return m, nil
+	}
+
+	m.err = ""
+	switch keyMsg.String() {
+	case "q", "esc", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.segments)-1, 0))
+	case "shift+up", "shift+k", "K":
+		if m.cursor > 0 {
+			m.segments[m.cursor-1], m.segments[m.cursor] = m.segments[m.cursor], m.segments[m.cursor-1]
+			m.cursor--
+		}
+	case "shift+down", "shift+j", "J":
+		if m.cursor < len(m.segments)-1 {
+			m.segments[m.cursor+1], m.segments[m.cursor] = m.segments[m.cursor], m.segments[m.cursor+1]
+			m.cursor++
+		}
+	case "a":
+		if len(m.segments) > 0 {
+			m.cursor++
+		}
+		m.segments = slices.Insert(m.segments, m.cursor, editSegment{})
+		return m.edit(editDuration, "")
+	case "enter", "e":
+		if len(m.segments) > 0 {
+			return m.edit(editDuration, m.segments[m.cursor].duration)
+		}
+	case "n":
+		if len(m.segments) > 0 {
+			return m.edit(editName, m.segments[m.cursor].name)
+		}
+	case "x", "d":
+		if len(m.segments) > 0 {
+			m.segments = slices.Delete(m.segments, m.cursor, m.cursor+1)
+			m.cursor = min(m.cursor, max(len(m.segments)-1, 0))
+		}
+	case "h":
+		return m.edit(editHook, m.hook)
+	case "r", "p":
+		if len(m.segments) == 0 {
+			m.err = "add a segment first"
+			return m, nil
+		}
+		m.action = editRun
+		if keyMsg.String() == "p" {
+			m.action = editPrint
+		}
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// edit starts typing into field, prefilled with value.
+func (m editModel) edit(field editField, value string) (tea.Model, tea.Cmd) {
+	m.field = field
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m, m.input.Focus()
+}
+
+// updateInput handles keys while a value is typed. Enter stores it, moving
+// on from the duration to the name of the segment, and esc discards it.
+func (m editModel) updateInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyPressMsg); ok {
+		switch msg.String() {
+		case "esc":
+			if m.field == editDuration && m.segments[m.cursor].duration == "" {
+				m.segments = slices.Delete(m.segments, m.cursor, m.cursor+1)
+				m.cursor = min(m.cursor, max(len(m.segments)-1, 0))
+			}
+			m.field = editNone
+			m.input.Blur()
+			return m, nil
+		case "enter":
+			value := strings.TrimSpace(m.input.Value())
+			switch m.field {
+			case editDuration:
+				if _, err := parseDurations([]string{value}); err != nil || value == "" {
+					m.err = fmt.Sprintf("invalid duration %q", value)
+					return m, nil
+				}
+				m.err = ""
+				m.segments[m.cursor].duration = value
+				return m.edit(editName, m.segments[m.cursor].name)
+			case editName:
+				m.segments[m.cursor].name = value
+			case editHook:
+				m.hook = value
+			}
+			m.field = editNone
+			m.input.Blur()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m editModel) View() string {
+	var b strings.Builder
+	b.WriteString(boldStyle.Render("Segments") + "\n")
+	if len(m.segments) == 0 {
+		b.WriteString(faintStyle.Render("  none yet, press a to add one") + "\n")
+	}
+	for i, seg := range m.segments {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		line := fmt.Sprintf("%s%d. %s", cursor, i+1, boldStyle.Render(seg.duration))
+		if seg.name != "" {
+			line += " " + italicStyle.Render(seg.name)
+		}
+		b.WriteString(line + "\n")
+	}
+	if m.hook != "" {
+		b.WriteString("exec: " + m.hook + "\n")
+	}
+
+	switch m.field {
+	case editDuration:
+		b.WriteString("\nDuration: " + m.input.View() + "\n")
+	case editName:
+		b.WriteString("\nName: " + m.input.View() + "\n")
+	case editHook:
+		b.WriteString("\nCommand to run on completion: " + m.input.View() + "\n")
+	}
+	if m.err != "" {
+		b.WriteString("\n" + m.err + "\n")
+	}
+
+	help := "a add • enter edit • n name • x delete • K/J move • h hook • r run • p print • q quit"
+	if m.field != editNone {
+		help = "enter confirm • esc cancel"
+	}
+	b.WriteString("\n" + faintStyle.Render(help))
+	return b.String()
+}
+
+// args returns the durations argument and names of the sequence.
+func (m editModel) args() (durations string, names string) {
+	ds := make([]string, len(m.segments))
+	ns := make([]string, len(m.segments))
+	named := false
+	for i, seg := range m.segments {
+		ds[i] = seg.duration
+		ns[i] = seg.name
+		named = named || seg.name != ""
+	}
+	if named {
+		names = strings.Join(ns, ",")
+	}
+	return strings.Join(ds, ","), names
+}
+
+// commandLine returns the toki invocation running the sequence.
+func (m editModel) commandLine() string {
+	durations, names := m.args()
+	line := "toki"
+	if names != "" {
+		line += " -n " + shellQuote(names)
+	}
+	if m.hook != "" {
+		line += " -e " + shellQuote(m.hook)
+	}
+	return line + " " + shellQuote(durations)
+}
+
+var shellSafeRe = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// shellQuote quotes s for POSIX shells unless it is safe as is.
+func shellQuote(s string) string {
+	if shellSafeRe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// clipboardCommands lists the commands tried, in order, per OS to copy their
+// standard input to the clipboard.
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"linux":   {{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}},
+	"windows": {{"clip"}},
+}
+
+func copyToClipboard(s string) error {
+	for _, args := range clipboardCommands[runtime.GOOS] {
+		bin, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		c := exec.Command(bin, args[1:]...)
+		c.Stdin = strings.NewReader(s)
+		return c.Run()
+	}
+	return fmt.Errorf("no clipboard command found")
+}
+
+var editClipboard bool
+
+var editCmd = &cobra.Command{
+	Use:          "edit",
+	Short:        "Builds a timer sequence interactively",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		res, err := tea.NewProgram(newEditModel()).Run()
+		if err != nil {
+			return err
+		}
+		m := res.(editModel)
+
+		switch m.action {
+		case editPrint:
+			line := m.commandLine()
+			fmt.Fprintln(cmd.OutOrStdout(), line)
+			if editClipboard {
+				if err := copyToClipboard(line); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not copy to the clipboard: %v\n", err)
+				}
+			}
+		case editRun:
+			durations, names := m.args()
+			name, execCommand = names, m.hook
+			separator = "," // the durations are joined by args, not typed by the user
+			return rootCmd.RunE(cmd, []string{durations})
+		}
+		return nil
+	},
+}
+
+func init() {
+	editCmd.Flags().BoolVarP(&editClipboard, "clipboard", "", false, "copy the printed command line to the clipboard")
+	rootCmd.AddCommand(editCmd)
+}
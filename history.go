@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/colorprofile"
+	"github.com/spf13/cobra"
+)
+
+// historyRecord is a single completed timer run. The history file holds one
+// JSON encoded record per line so new runs can simply be appended.
+type historyRecord struct {
+	Name        string            `json:"name"`
+	Durations   []string          `json:"durations"`
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  time.Time         `json:"finished_at"`
+	Interrupted bool              `json:"interrupted"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+func defaultHistoryPath() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "toki", "history.json")
+}
+
+func appendHistory(path string, rec historyRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(rec); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readHistory(path string) ([]historyRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []historyRecord
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var rec historyRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+func formatDurations(durations []time.Duration) []string {
+	s := make([]string, len(durations))
+	for i, d := range durations {
+		s[i] = d.String()
+	}
+	return s
+}
+
+var (
+	historyLimit int
+	historyJSON  bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:          "history",
+	Short:        "Shows recently completed timers",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		records, err := readHistory(logPath)
+		if err != nil {
+			return err
+		}
+		if historyLimit > 0 && len(records) > historyLimit {
+			records = records[len(records)-historyLimit:]
+		}
+
+		if historyJSON {
+			if records == nil {
+				records = []historyRecord{}
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(records)
+		}
+
+		out := colorprofile.NewWriter(cmd.OutOrStdout(), os.Environ())
+		for _, rec := range records {
+			line := boldStyle.Render(rec.FinishedAt.Local().Format("2006-01-02 15:04")) +
+				"  " + strings.Join(rec.Durations, ",")
+			if rec.Name != "" {
+				line += "  " + italicStyle.Render(rec.Name)
+			}
+			fmt.Fprintln(out, line)
+		}
+		return nil
+	},
+}
+
+var (
+	statsFrom string
+	statsTo   string
+	statsTags map[string]string
+)
+
+// hasTags reports whether rec has every tag in tags.
+func (rec historyRecord) hasTags(tags map[string]string) bool {
+	for k, v := range tags {
+		if tag, ok := rec.Tags[k]; !ok || tag != v {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDay parses a YYYY-MM-DD date in local time.
+func parseDay(s string) (time.Time, error) {
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+var statsCmd = &cobra.Command{
+	Use:          "stats",
+	Short:        "Summarizes completed timers",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		records, err := readHistory(logPath)
+		if err != nil {
+			return err
+		}
+
+		var from time.Time
+		to := time.Now()
+		if statsFrom != "" {
+			if from, err = parseDay(statsFrom); err != nil {
+				return err
+			}
+		}
+		if statsTo != "" {
+			if to, err = parseDay(statsTo); err != nil {
+				return err
+			}
+			to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+
+		var total time.Duration
+		var sessions int
+		names := map[string]int{}
+		days := map[string]int{}
+		for _, rec := range records {
+			if rec.FinishedAt.Before(from) || rec.FinishedAt.After(to) || !rec.hasTags(statsTags) {
+				continue
+			}
+			sessions++
+			total += rec.FinishedAt.Sub(rec.StartedAt)
+			if rec.Name != "" {
+				names[rec.Name]++
+			}
+			days[rec.FinishedAt.Local().Format(time.DateOnly)]++
+		}
+
+		out := colorprofile.NewWriter(cmd.OutOrStdout(), os.Environ())
+		fmt.Fprintf(out, "%s %d\n", boldStyle.Render("Sessions:"), sessions)
+		if sessions == 0 {
+			return nil
+		}
+		fmt.Fprintf(out, "%s %s\n", boldStyle.Render("Total:"), total.Round(time.Second))
+		fmt.Fprintf(out, "%s %s\n", boldStyle.Render("Average:"), (total / time.Duration(sessions)).Round(time.Second))
+
+		if len(names) > 0 {
+			fmt.Fprintln(out, boldStyle.Render("Most used:"))
+			for _, n := range topNames(names, 5) {
+				fmt.Fprintf(out, "  %s %d\n", padRight(n, 20), names[n])
+			}
+		}
+
+		fmt.Fprintln(out, boldStyle.Render("Last 30 days:"))
+		peak := 0
+		for _, count := range days {
+			peak = max(peak, count)
+		}
+		for i := 29; i >= 0; i-- {
+			day := to.AddDate(0, 0, -i).Format(time.DateOnly)
+			count := days[day]
+			bar := strings.Repeat("█", count*40/peak)
+			fmt.Fprintf(out, "  %s %s %d\n", day, bar, count)
+		}
+		return nil
+	},
+}
+
+// topNames returns up to n keys of counts, most frequent first.
+func topNames(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&statsFrom, "from", "", "", "only include timers finished on or after this date (YYYY-MM-DD)")
+	statsCmd.Flags().StringVarP(&statsTo, "to", "", "", "only include timers finished on or before this date (YYYY-MM-DD)")
+	statsCmd.Flags().StringToStringVarP(&statsTags, "tag", "", nil, "only include timers with this tag, as key=value (repeatable)")
+	rootCmd.AddCommand(statsCmd)
+
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "", 10, "number of entries to show (0 for all)")
+	historyCmd.Flags().BoolVarP(&historyJSON, "json", "", false, "print entries as JSON")
+
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+// presetNameRe matches the characters replaced by dashes in imported preset
+// names.
+var presetNameRe = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// readToggl reads a Toggl CSV export and returns the average duration of the
+// entries of each project, or of each description for entries without a
+// project, keyed by the preset name derived from it.
+func readToggl(r io.Reader) (map[string]time.Duration, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty export")
+	}
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	durationCol, ok := columns["Duration"]
+	if !ok {
+		return nil, fmt.Errorf("no Duration column, expected a Toggl detailed CSV export")
+	}
+	field := func(rec []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	totals := map[string]time.Duration{}
+	counts := map[string]int{}
+	for line, rec := range records[1:] {
+		group := cmp.Or(field(rec, "Project"), field(rec, "Description"))
+		name := strings.Trim(presetNameRe.ReplaceAllString(strings.ToLower(group), "-"), "-")
+		if name == "" || durationCol >= len(rec) {
+			continue
+		}
+		d, err := parseClockDuration(rec[durationCol])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		totals[name] += d
+		counts[name]++
+	}
+
+	averages := make(map[string]time.Duration, len(totals))
+	for name, total := range totals {
+		averages[name] = max((total / time.Duration(counts[name])).Round(time.Minute), time.Minute)
+	}
+	return averages, nil
+}
+
+// parseClockDuration parses a duration written as HH:MM:SS.
+func parseClockDuration(s string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid duration %q, expected HH:MM:SS", s)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q, expected HH:MM:SS", s)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+// formatPresetDuration formats d without the zero units of time.Duration's
+// String, e.g. 25m instead of 25m0s.
+func formatPresetDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+var importFrom string
+
+var importCmd = &cobra.Command{
+	Use:          "import --from toggl <export.csv>",
+	Short:        "Creates presets from the average durations of a time tracking export",
+	SilenceUsage: true,
+	Args:         cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if importFrom != "toggl" {
+			return fmt.Errorf("unknown import source %q, expected toggl", importFrom)
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		averages, err := readToggl(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if len(averages) == 0 {
+			return fmt.Errorf("%s: no entries to import", args[0])
+		}
+
+		// Imported presets replace those of the same name and keep the others.
+		path := presetsPath()
+		presets := map[string]string{}
+		if _, err := toml.DecodeFile(path, &presets); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for name, d := range averages {
+			presets[name] = formatPresetDuration(d)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		out, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := toml.NewEncoder(out).Encode(presets); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(averages)) {
+			cmd.Printf("%s = %s\n", name, presets[name])
+		}
+		cmd.Printf("imported %d presets to %s\n", len(averages), path)
+		return nil
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVarP(&importFrom, "from", "", "", "format of the export, possible values: toggl")
+	_ = importCmd.MarkFlagRequired("from")
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/spf13/cobra"
+)
+
+// instance is the state each running toki writes to its pid file so that
+// other toki processes can find it.
+type instance struct {
+	PID       int           `json:"pid"`
+	Name      string        `json:"name"`
+	EndsAt    time.Time     `json:"ends_at"`
+	Paused    bool          `json:"paused"`
+	Remaining time.Duration `json:"remaining"`
+}
+
+func instanceDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "toki")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("toki-%d", os.Getuid()))
+}
+
+func instancePath(pid int) string {
+	return filepath.Join(instanceDir(), strconv.Itoa(pid)+".json")
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// writeInstance returns a command updating the pid file of this process with
+// the running segment. Failures are ignored as the pid file is best effort.
+func (m model) writeInstance() tea.Cmd {
+	if m.instanceFile == "" {
+		return nil
+	}
+	inst := instance{
+		PID:       os.Getpid(),
+		Name:      m.segmentName(),
+		EndsAt:    time.Now().Add(m.timer.Timeout),
+		Paused:    m.paused,
+		Remaining: m.timer.Timeout,
+	}
+	path := m.instanceFile
+	return func() tea.Msg {
+		if data, err := json.Marshal(inst); err == nil {
+			_ = writeFileAtomic(path, data)
+		}
+		return nil
+	}
+}
+
+// timerState is written to the --state-file on every tick.
+type timerState struct {
+	Name        string  `json:"name"`
+	RemainingMS int64   `json:"remaining_ms"`
+	TotalMS     int64   `json:"total_ms"`
+	Pct         float64 `json:"pct"`
+}
+
+// saveState writes the progress of the running segment to the state file.
+func (m model) saveState() error {
+	total := m.durations[m.state]
+	data, err := json.Marshal(timerState{
+		Name:        m.segmentName(),
+		RemainingMS: max(total-m.passed, 0).Milliseconds(),
+		TotalMS:     total.Milliseconds(),
+		Pct:         min(float64(m.passed)/float64(total), 1),
+	})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(m.stateFile, data)
+}
+
+// writeState returns a command saving the state file, if one was requested.
+// The file is validated on startup so later failures are ignored.
+func (m model) writeState() tea.Cmd {
+	if m.stateFile == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		_ = m.saveState()
+		return nil
+	}
+}
+
+// readInstances returns the running toki instances, removing pid files left
+// behind by processes that no longer exist.
+func readInstances() ([]instance, error) {
+	entries, err := os.ReadDir(instanceDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var instances []instance
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(instanceDir(), entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var inst instance
+		if err := json.Unmarshal(data, &inst); err != nil {
+			continue
+		}
+		if !processAlive(inst.PID) {
+			_ = os.Remove(path)
+			_ = os.Remove(controlPath(inst.PID))
+			continue
+		}
+		instances = append(instances, inst)
+	}
+	return instances, nil
+}
+
+var listCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "Lists running timers",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		instances, err := readInstances()
+		if err != nil {
+			return err
+		}
+		if len(instances) == 0 {
+			cmd.Println("no running timers")
+			return nil
+		}
+
+		rows := [][]string{{"PID", "NAME", "REMAINING"}}
+		for _, inst := range instances {
+			remaining := time.Until(inst.EndsAt).Round(time.Second).String()
+			if inst.Paused {
+				remaining = inst.Remaining.Round(time.Second).String() + " (paused)"
+			}
+			rows = append(rows, []string{strconv.Itoa(inst.PID), inst.Name, remaining})
+		}
+		return writeTable(cmd.OutOrStdout(), rows)
+	},
+}
+
+var cancelCmd = &cobra.Command{
+	Use:          "cancel <name>",
+	Short:        "Cancels running timers by name",
+	SilenceUsage: true,
+	Args:         cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		instances, err := readInstances()
+		if err != nil {
+			return err
+		}
+		var matches []instance
+		for _, inst := range instances {
+			if inst.Name == args[0] {
+				matches = append(matches, inst)
+			}
+		}
+
+		switch len(matches) {
+		case 0:
+			return fmt.Errorf("no running timer named %q", args[0])
+		case 1:
+		default:
+			for _, inst := range matches {
+				cmd.Printf("%d\t%s\n", inst.PID, inst.Name)
+			}
+			cmd.Printf("Cancel all %d timers? [y/N] ", len(matches))
+			answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+				return nil
+			}
+		}
+
+		for _, inst := range matches {
+			if err := terminateProcess(inst.PID); err != nil {
+				return fmt.Errorf("could not cancel %d: %w", inst.PID, err)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(cancelCmd)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+)
+
+// keyActions maps the actions of the [keybindings] config section and of
+// --keybind to the bindings they replace.
+var keyActions = map[string]*key.Binding{
+	"quit":      &quitKeys,
+	"interrupt": &intKeys,
+	"pause":     &pauseKeys,
+	"skip":      &skipKeys,
+	"restart":   &restartKeys,
+}
+
+// loadKeybindings rebinds the actions listed in the [keybindings] section of
+// the config file, e.g. quit = ["x", "esc"], and then those given as
+// action=keys with --keybind, which take precedence.
+func loadKeybindings(flags []string) error {
+	bindings := map[string][]string{}
+	if err := configSection("keybindings", &bindings); err != nil {
+		return err
+	}
+	for _, v := range flags {
+		action, keys, ok := strings.Cut(v, "=")
+		if !ok {
+			return fmt.Errorf("invalid --keybind %q, expected action=keys, e.g. quit=x,esc", v)
+		}
+		bindings[strings.TrimSpace(action)] = strings.Split(keys, ",")
+	}
+
+	for action, keys := range bindings {
+		binding, ok := keyActions[action]
+		if !ok {
+			return fmt.Errorf("unknown key action %q, expected quit, interrupt, pause, skip or restart", action)
+		}
+		var names []string
+		for _, k := range keys {
+			if k = strings.TrimSpace(k); k != "" {
+				names = append(names, k)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no keys given for %s", action)
+		}
+		*binding = key.NewBinding(key.WithKeys(names...))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// glyphs holds a 7-segment style font, five rows high, for the characters
+// needed to render a countdown.
+var glyphs = map[rune][5]string{
+	'0': {"███", "█ █", "█ █", "█ █", "███"},
+	'1': {"  █", "  █", "  █", "  █", "  █"},
+	'2': {"███", "  █", "███", "█  ", "███"},
+	'3': {"███", "  █", "███", "  █", "███"},
+	'4': {"█ █", "█ █", "███", "  █", "  █"},
+	'5': {"███", "█  ", "███", "  █", "███"},
+	'6': {"███", "█  ", "███", "█ █", "███"},
+	'7': {"███", "  █", "  █", "  █", "  █"},
+	'8': {"███", "█ █", "███", "█ █", "███"},
+	'9': {"███", "█ █", "███", "  █", "███"},
+	':': {" ", "█", " ", "█", " "},
+}
+
+// largeClock formats d as h:mm:ss, or m:ss when under an hour.
+func largeClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < 0 {
+		d = 0
+	}
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// renderLarge renders s with the block digit font. Characters without a
+// glyph are skipped.
+func renderLarge(s string) string {
+	var rows [5][]string
+	for _, r := range s {
+		g, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		for i := range rows {
+			rows[i] = append(rows[i], g[i])
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = strings.Join(row, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// largeView renders the countdown as block digits above the progress bar,
+// centred on the bar's width.
+func (m model) largeView() string {
+	var header string
+	if name := m.segmentName(); name != "" {
+		header = italicStyle.Render(name)
+	}
+	if m.paused && !m.dimOnPause {
+		if header != "" {
+			header += " - "
+		}
+		header += boldStyle.Render(tr("paused"))
+	}
+	header = strings.TrimSpace(m.segmentIndicator() + header)
+
+	width := m.progress.Width()
+	clock := m.timer.Timeout
+	if m.elapsed {
+		clock = m.passed
+	}
+	style := boldStyle
+	if m.overtimeRunning() {
+		clock, style = time.Since(m.overSince), overtimeStyle
+	}
+	digits := style.Render(renderLarge(largeClock(clock)))
+	parts := []string{lipgloss.PlaceHorizontal(width, lipgloss.Center, digits)}
+	if header != "" {
+		parts = append([]string{lipgloss.PlaceHorizontal(width, lipgloss.Center, header)}, parts...)
+	}
+	if !m.noProgress {
+		parts = append(parts, m.barView())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
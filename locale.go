@@ -0,0 +1,70 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// messages holds the display strings of the locale picked by loadLocale,
+// on top of the English ones.
+var messages map[string]string
+
+// readLocale reads the embedded display strings of locale, e.g. "de".
+func readLocale(locale string) (map[string]string, error) {
+	data, err := localeFiles.ReadFile("locales/" + locale + ".json")
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("locale %s: %w", locale, err)
+	}
+	return m, nil
+}
+
+// loadLocale selects the display strings of the --locale name, or of the
+// language in LANG when it is empty. An unsupported language in LANG falls
+// back to English while an unsupported --locale is an error.
+func loadLocale(name string) error {
+	explicit := name != ""
+	if !explicit {
+		name = os.Getenv("LANG")
+	}
+	// LANG looks like de_DE.UTF-8, only the language is used.
+	lang, _, _ := strings.Cut(strings.ToLower(name), ".")
+	lang, _, _ = strings.Cut(lang, "_")
+	lang, _, _ = strings.Cut(lang, "-")
+
+	var err error
+	if messages, err = readLocale("en"); err != nil {
+		return err
+	}
+	if lang == "" || lang == "en" {
+		return nil
+	}
+	translated, err := readLocale(lang)
+	if err != nil {
+		if explicit {
+			return fmt.Errorf("unknown locale %q, expected en, de, fr or ja", name)
+		}
+		return nil
+	}
+	for key, s := range translated {
+		messages[key] = s
+	}
+	return nil
+}
+
+// tr returns the display string key of the selected locale.
+func tr(key string) string {
+	if s, ok := messages[key]; ok {
+		return s
+	}
+	return key
+}
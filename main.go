@@ -1,62 +1,226 @@
 package main
 
 import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
 	"os"
+	"os/exec"
+	"os/signal"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/charmbracelet/bubbles/v2/key"
 	"github.com/charmbracelet/bubbles/v2/progress"
 	"github.com/charmbracelet/bubbles/v2/timer"
 	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/colorprofile"
 	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 	mcobra "github.com/muesli/mango-cobra"
 	"github.com/muesli/roff"
 	"github.com/spf13/cobra"
 )
 
 type model struct {
-	name            string
+	names           []string
 	altscreen       bool
+	bell            bool
+	beepInterval    time.Duration
+	lastBeep        time.Duration
+	large           bool
+	noProgress      bool
+	fixedWidth      bool
+	autoColor       bool
+	width           int
+	elapsed         bool
+	countUp         bool
+	overtime        bool
+	overSince       time.Time
+	after           time.Duration
+	grace           time.Duration
+	showingDone     bool
+	embedded        bool
+	flashThreshold  int
+	titleProgress   bool
+	windowTitle     string
+	shownTitle      string
+	noTotal         bool
+	noIndicator     bool
+	showSpark       bool
+	spark           sparkline
+	timerFormat     string
+	labelPosition   string
+	prefix          string
+	suffix          string
+	instanceFile    string
+	stateFile       string
+	onStart         string
+	onSegmentStart  string
+	segmentExecs    []string
+	segmentColors   []barColor
+	baseColor       barColor
+	pctHooks        []pctHook
+	tmuxPane        string
+	tmuxFormat      *template.Template
+	countdownFormat *template.Template
 	startTimeFormat string
+	location        *time.Location
 	durations       []time.Duration
+	interval        time.Duration
+	speed           int
+	delays          []bool
 	state           int
 	passed          time.Duration
 	start           time.Time
+	sessionStart    time.Time
 	timer           timer.Model
-	progress        progress.Model
+	progress        urgencyBar
+	totalBar        progress.Model
+	noTotalBar      bool
 	repeat          int
+	repeatCount     int
 	quitting        bool
+	completed       bool
 	interrupting    bool
+	terminated      bool
+	paused          bool
+	dimOnPause      bool
+	hideStartTime   bool
+	hideEndTime     bool
+	wallclock       bool
+	pipe            *bufio.Reader
+	pausedAt        time.Time
+	pausedFor       time.Duration
+	waitingForStart bool
+	startAt         time.Time
 }
 
 func (m model) Init() tea.Cmd {
-	return m.timer.Init()
+	var requestColor tea.Cmd
+	if m.autoColor {
+		requestColor = tea.RequestBackgroundColor
+	}
+	if m.waitingForStart {
+		// The pid file is written once the timer starts, see startTimer.
+		return tea.Batch(m.startAtTick(), requestColor, m.readPipe())
+	}
+	return tea.Batch(m.startTimer(), requestColor, m.readPipe(), func() tea.Msg { return windowTitleMsg{} })
+}
+
+// startTimer returns the commands starting the first segment.
+func (m model) startTimer() tea.Cmd {
+	return tea.Batch(
+		m.timer.Init(),
+		m.writeInstance(),
+		tea.Sequence(m.runHook(m.onStart), m.runHook(m.onSegmentStart)),
+	)
+}
+
+// signalMsg is sent when toki receives SIGINT or SIGTERM, e.g. from toki
+// cancel.
+type signalMsg struct {
+	signal os.Signal
+}
+
+// forwardSignals replaces the signal handler of Bubble Tea so that a
+// terminated timer still shuts down through Update. The returned function
+// restores the default handling and must be called once p exited, so that
+// toki can still be interrupted while it finishes up.
+func forwardSignals(p *tea.Program) func() {
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case s := <-sig:
+			p.Send(signalMsg{s})
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case timer.TickMsg:
+		if msg.ID != m.timer.ID() || !m.timer.Running() {
+			return m, nil
+		}
+
 		var cmds []tea.Cmd
 		var cmd tea.Cmd
 
-		m.passed += m.timer.Interval
-		pct := m.passed.Milliseconds() * 100 / m.durations[m.state].Milliseconds()
-		cmds = append(cmds, m.progress.SetPercent(float64(pct)/100))
+		// With --speed every tick covers several intervals, the timer
+		// itself only takes one off.
+		m.timer.Timeout -= m.timer.Interval * time.Duration(m.speed-1)
+		m.passed = min(m.passed+m.timer.Interval*time.Duration(m.speed), m.durations[m.state])
+		slog.Debug("TICK", "state", m.state, "passed", m.passed,
+			"pct", fmt.Sprintf("%.2f%%", float64(m.passed)/float64(m.durations[m.state])*100))
+		if !m.noProgress {
+			pct := float64(m.passed.Milliseconds()*100/m.durations[m.state].Milliseconds()) / 100
+			if m.countUp {
+				// There is no end, so the bar fills up once a minute.
+				pct = float64(m.passed%time.Minute) / float64(time.Minute)
+			}
+			cmds = append(cmds, m.progress.SetPercent(pct))
+			if m.showSpark {
+				m.spark.Push(pct)
+			}
+			if m.showTotalBar() {
+				cmds = append(cmds, m.totalBar.SetPercent(m.totalPercent()))
+			}
+		}
+
+		if !m.isDelay() {
+			for i, h := range m.pctHooks {
+				if !h.fired && float64(m.passed) >= h.pct/100*float64(m.durations[m.state]) {
+					m.pctHooks[i].fired = true
+					cmds = append(cmds, m.runHook(h.command))
+				}
+			}
+		}
+
+		if m.beepInterval > 0 && m.passed-m.lastBeep >= m.beepInterval && m.passed < m.durations[m.state] {
+			m.lastBeep = m.passed
+			cmds = append(cmds, tea.Raw("\a"))
+		}
 
 		m.timer, cmd = m.timer.Update(msg)
-		cmds = append(cmds, cmd)
+		cmds = append(cmds, cmd, m.writeState(), m.writeTmux(), m.updateTitle(), m.updateWindowTitle())
 		return m, tea.Batch(cmds...)
 
 	case tea.WindowSizeMsg:
-		m.progress.SetWidth(msg.Width - padding*2 - 4)
 		winHeight = msg.Height
-		if !m.altscreen && m.progress.Width() > maxWidth {
+		m.width = msg.Width
+		if m.fixedWidth {
+			return m, nil
+		}
+		width := msg.Width - padding*2 - 4
+		if m.labelPosition == "right" {
+			width -= lipgloss.Width(m.labelView()) + 1
+		}
+		m.progress.SetWidth(max(width, 1))
+		if !m.altscreen && maxWidth > 0 && m.progress.Width() > maxWidth {
 			m.progress.SetWidth(maxWidth)
 		}
+		if m.showSpark {
+			m.progress.SetWidth(max(m.progress.Width()-len(m.spark.values)-1, 1))
+		}
+		m.totalBar.SetWidth(m.progress.Width())
 		return m, nil
 
 	case timer.StartStopMsg:
@@ -65,143 +229,1431 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case timer.TimeoutMsg:
+		if msg.ID != m.timer.ID() {
+			return m, nil
+		}
+		// The hook of the finished segment runs before the next one starts.
+		hook := m.runHook(m.segmentExec())
+		next, cmd := m.advanceSegment()
+		return next, sequence(hook, cmd)
 
-		if m.state == len(m.durations)-1 {
-			if m.repeat == 0 {
-				m.quitting = true
-				return m, tea.Quit
-			} else if m.repeat > 0 {
-				m.repeat--
-			}
-			m.state = 0
-		} else {
-			m.state++
+	case progress.FrameMsg:
+		var cmd, totalCmd tea.Cmd
+		m.progress, cmd = m.progress.Update(msg)
+		m.totalBar, totalCmd = m.totalBar.Update(msg)
+		return m, tea.Batch(cmd, totalCmd)
+
+	case tea.BackgroundColorMsg:
+		if m.autoColor {
+			m.baseColor = autoProgressColor(msg.IsDark())
+			m.progress.SetBaseColor(m.segmentColor())
 		}
+		return m, nil
 
-		m.start = time.Now()
-		m.passed = 0
+	case overtimeTickMsg:
+		return m, overtimeTick()
 
-		interval := timerInterval(m.durations[m.state])
-		m.timer = timer.New(m.durations[m.state], timer.WithInterval(interval))
+	case afterMsg:
+		return m.end()
 
-		return m, m.timer.Start()
+	case windowTitleMsg:
+		return m, m.updateWindowTitle()
 
-	case progress.FrameMsg:
-		var cmd tea.Cmd
-		m.progress, cmd = m.progress.Update(msg)
-		return m, cmd
+	case graceMsg:
+		m.quitting = true
+		return m, m.quit()
+
+	case startAtTickMsg:
+		if !m.waitingForStart {
+			return m, nil
+		}
+		if time.Now().Before(m.startAt) {
+			return m, m.startAtTick()
+		}
+		m.waitingForStart = false
+		m.start, m.sessionStart = time.Now(), time.Now()
+		return m, m.startTimer()
+
+	case signalMsg:
+		m.interrupting = true
+		m.terminated = msg.signal == syscall.SIGTERM
+		return m, sequence(m.writeState(), m.quit())
+
+	case controlMsg:
+		switch {
+		case msg.command == "quit" || msg.command == "stop":
+			m.quitting = true
+			return m, m.quit()
+		case m.waitingForStart || m.overtimeRunning() || m.completed:
+			return m, nil
+		case msg.command == "pause" && !m.paused, msg.command == "resume" && m.paused:
+			return m.togglePause()
+		case msg.command == "skip":
+			return m.advanceSegment()
+		case msg.command == "restart":
+			return m.restartSegment()
+		}
+
+	case pipeMsg:
+		next, cmd := m.Update(controlMsg(msg))
+		return next, tea.Batch(cmd, m.readPipe())
 
 	case tea.KeyMsg:
 		if key.Matches(msg, quitKeys) {
 			m.quitting = true
-			return m, tea.Quit
+			return m, m.quit()
 		}
 		if key.Matches(msg, intKeys) {
 			m.interrupting = true
-			return m, tea.Quit
+			return m, m.quit()
+		}
+		if m.waitingForStart {
+			if msg.String() != "enter" || !m.startAt.IsZero() {
+				return m, nil
+			}
+			m.waitingForStart = false
+			m.start, m.sessionStart = time.Now(), time.Now()
+			return m, m.startTimer()
+		}
+		if m.overtimeRunning() || m.completed {
+			return m, nil
+		}
+		if key.Matches(msg, pauseKeys) {
+			return m.togglePause()
+		}
+		if key.Matches(msg, skipKeys) {
+			return m.advanceSegment()
+		}
+		if key.Matches(msg, restartKeys) {
+			return m.restartSegment()
 		}
 	}
 
 	return m, nil
 }
 
+// advanceSegment starts the next segment, wrapping around while repeats are
+// left, or quits after the last one.
+func (m model) advanceSegment() (tea.Model, tea.Cmd) {
+	previous := m.state
+	if m.state == len(m.durations)-1 {
+		m.repeatCount++
+		if m.repeat >= 0 && m.repeatCount >= m.repeat && m.overtime {
+			m.completed = true
+			m.overSince = time.Now()
+			return m, sequence(m.ringBell(), overtimeTick())
+		}
+		if m.repeat >= 0 && m.repeatCount >= m.repeat && m.after > 0 {
+			m.completed = true
+			return m, sequence(m.ringBell(), tea.Tick(m.after, func(time.Time) tea.Msg {
+				return afterMsg{}
+			}))
+		}
+		if m.repeat >= 0 && m.repeatCount >= m.repeat {
+			m.completed = true
+			next, cmd := m.end()
+			return next, sequence(m.ringBell(), cmd)
+		}
+		m.state = m.repeatStart()
+	} else {
+		m.state++
+	}
+	slog.Debug("TRANSITION", "state", fmt.Sprintf("%d->%d", previous, m.state))
+
+	m.start = time.Now()
+	m.passed = 0
+	m.lastBeep = 0
+	m.paused = false
+	m.pausedFor = 0
+
+	m.timer = m.segmentTimer()
+	m.resetPctHooks()
+	if !m.noProgress && len(m.segmentColors) > 0 {
+		m.progress.SetBaseColor(m.segmentColor())
+	}
+
+	return m, tea.Batch(m.timer.Start(), m.ringBell(), m.writeInstance(), m.runHook(m.onSegmentStart))
+}
+
+// pctHook is an --on-pct command run once the segment reached pct percent.
+type pctHook struct {
+	pct     float64
+	command string
+	fired   bool
+}
+
+// parsePctHook parses an --on-pct value of the form "50:command".
+func parsePctHook(s string) (pctHook, error) {
+	pct, command, ok := strings.Cut(s, ":")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(pct), "%"), 64)
+	if !ok || err != nil || v < 0 || v > 100 || command == "" {
+		return pctHook{}, fmt.Errorf("invalid --on-pct %q, expected e.g. 50:command", s)
+	}
+	return pctHook{pct: v, command: command}, nil
+}
+
+// resetPctHooks arms the --on-pct hooks again for a new segment.
+func (m *model) resetPctHooks() {
+	hooks := make([]pctHook, len(m.pctHooks))
+	for i, h := range m.pctHooks {
+		hooks[i] = pctHook{pct: h.pct, command: h.command}
+	}
+	m.pctHooks = hooks
+}
+
+// segmentTimer returns a new timer for the running segment, ticking at the
+// --interval if one was given.
+func (m model) segmentTimer() timer.Model {
+	interval := m.interval
+	if interval == 0 {
+		interval = timerInterval(m.durations[m.state])
+	}
+	return timer.New(m.durations[m.state], timer.WithInterval(interval))
+}
+
+// runHook returns a command running a shell hook for the current segment,
+// replacing %n with the segment name and %i with its index. Hooks run while
+// the display is active so their output is discarded.
+func (m model) runHook(command string) tea.Cmd {
+	if command == "" {
+		return nil
+	}
+	r := strings.NewReplacer("%n", m.segmentName(), "%i", strconv.Itoa(m.state))
+	c := exec.Command("sh", "-c", r.Replace(command))
+	return func() tea.Msg {
+		_ = c.Run()
+		return nil
+	}
+}
+
+// restartSegment starts the running segment over from the beginning.
+func (m model) restartSegment() (tea.Model, tea.Cmd) {
+	m.start = time.Now()
+	m.passed = 0
+	m.lastBeep = 0
+	m.paused = false
+	m.pausedFor = 0
+
+	m.timer = m.segmentTimer()
+	m.resetPctHooks()
+
+	cmds := []tea.Cmd{m.timer.Start(), m.writeInstance()}
+	if !m.noProgress {
+		cmds = append(cmds, m.progress.SetPercent(0))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// isDelay reports whether the running segment is a +offset delay before the
+// timer starts.
+func (m model) isDelay() bool {
+	return m.state < len(m.delays) && m.delays[m.state]
+}
+
+// repeatStart returns the segment repeats start from, skipping the leading
+// delays as they only apply before the first run.
+func (m model) repeatStart() int {
+	start := 0
+	for start < len(m.delays) && m.delays[start] && start < len(m.durations)-1 {
+		start++
+	}
+	return start
+}
+
+// segmentName returns the name of the running segment.
+func (m model) segmentName() string {
+	return m.nameOf(m.state)
+}
+
+// segmentExec returns the command to run when the running segment completes,
+// as set by exec_on_complete in a timer file.
+func (m model) segmentExec() string {
+	if m.state < len(m.segmentExecs) {
+		return m.segmentExecs[m.state]
+	}
+	return ""
+}
+
+// segmentColor returns the progress bar color of the running segment, as
+// set by color in a timer file, or the color of the theme.
+func (m model) segmentColor() barColor {
+	if m.state < len(m.segmentColors) && m.segmentColors[m.state].from != "" {
+		return m.segmentColors[m.state]
+	}
+	return m.baseColor
+}
+
+// nameOf returns the name of segment i. A single name applies to every
+// segment, otherwise names are matched to segments by position and an empty
+// name leaves its segment unnamed.
+func (m model) nameOf(i int) string {
+	if len(m.names) == 1 {
+		return m.names[0]
+	}
+	if i < len(m.names) {
+		return m.names[i]
+	}
+	return ""
+}
+
+// writePlan writes the segments of the session with their names and
+// durations, followed by the total, for --dry-run.
+func (m model) writePlan(w io.Writer) error {
+	rows := [][]string{{"#", "NAME", "DURATION"}}
+	// The leading delays only run once, the other segments on every repeat.
+	var once, each time.Duration
+	start := m.repeatStart()
+	for i, d := range m.durations {
+		duration := formatPresetDuration(d)
+		if m.delays[i] {
+			duration = "+" + duration
+		}
+		rows = append(rows, []string{strconv.Itoa(i + 1), m.nameOf(i), duration})
+		if i < start {
+			once += d
+		} else {
+			each += d
+		}
+	}
+	if err := writeTable(w, rows); err != nil {
+		return err
+	}
+	total := formatPresetDuration(each)
+	if once > 0 {
+		total = formatPresetDuration(once) + " + " + total
+	}
+	switch {
+	case m.repeat < 0:
+		_, err := fmt.Fprintf(w, "Total: %s, repeated forever\n", total)
+		return err
+	case m.repeat > 1:
+		_, err := fmt.Fprintf(w, "Total: %s × %d = %s\n", total, m.repeat, formatPresetDuration(once+each*time.Duration(m.repeat)))
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Total: %s\n", formatPresetDuration(once+each))
+	return err
+}
+
+// countdownParts is the data available to the --countdown-format template.
+type countdownParts struct {
+	H, M, S int
+}
+
+// timerView renders the remaining time, or the elapsed time with --elapsed,
+// in the --countdown-format or --timer-format.
+func (m model) timerView() string {
+	d := m.timer.Timeout
+	if m.elapsed {
+		d = m.passed
+	}
+	if m.countdownFormat != nil {
+		var b strings.Builder
+		s := int(d.Seconds())
+		if err := m.countdownFormat.Execute(&b, countdownParts{H: s / 3600, M: s / 60 % 60, S: s % 60}); err != nil {
+			return err.Error()
+		}
+		return b.String()
+	}
+	switch m.timerFormat {
+	case "hms":
+		return d.String()
+	case "seconds":
+		return strconv.Itoa(int(d.Seconds()))
+	case "colon":
+		d = d.Truncate(time.Second)
+		if d >= time.Hour {
+			return formatClock(d)
+		}
+		return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	if m.elapsed {
+		return formatClock(d)
+	}
+	return m.timer.View()
+}
+
+// overtimeRunning reports whether the last segment ran out with --overtime.
+func (m model) overtimeRunning() bool {
+	return !m.overSince.IsZero()
+}
+
+// overtimeView renders the time since the timer ran out.
+func (m model) overtimeView() string {
+	d := time.Since(m.overSince).Truncate(time.Second)
+	if m.timerFormat == "seconds" {
+		return strconv.Itoa(int(d.Seconds()))
+	}
+	return formatClock(d)
+}
+
+// afterMsg ends the --after wait once all segments completed.
+type afterMsg struct{}
+
+// graceMsg ends the --grace display of the done message.
+type graceMsg struct{}
+
+// end quits the completed timer, after showing the done message for the
+// --grace duration if one was given.
+func (m model) end() (tea.Model, tea.Cmd) {
+	if m.embedded {
+		return m, nil // toki multi quits once all its timers completed
+	}
+	if m.grace > 0 {
+		m.showingDone = true
+		return m, tea.Tick(m.grace, func(time.Time) tea.Msg {
+			return graceMsg{}
+		})
+	}
+	m.quitting = true
+	return m, m.quit()
+}
+
+// overtimeTickMsg redraws the overtime once a second.
+type overtimeTickMsg struct{}
+
+func overtimeTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return overtimeTickMsg{}
+	})
+}
+
+// startAtTickMsg redraws the wait for --start-at once a second and starts
+// the timer once the time is reached.
+type startAtTickMsg struct{}
+
+func (m model) startAtTick() tea.Cmd {
+	if m.startAt.IsZero() {
+		return nil
+	}
+	return tea.Tick(min(time.Until(m.startAt), time.Second), func(time.Time) tea.Msg {
+		return startAtTickMsg{}
+	})
+}
+
+// updateTitle returns a command showing the progress of the running segment
+// in the terminal title with OSC 0, or nil when --title-progress is not set.
+func (m model) updateTitle() tea.Cmd {
+	if !m.titleProgress {
+		return nil
+	}
+	pct := int(min(float64(m.passed)/float64(m.durations[m.state]), 1) * 100)
+	title := fmt.Sprintf("toki: %d%%", pct)
+	if name := m.segmentName(); name != "" {
+		title += " – " + name
+	}
+	return tea.Raw(ansi.SetIconNameWindowTitle(title))
+}
+
+// windowTitleMsg sets the --title on startup, through Update so that the
+// title shown is kept.
+type windowTitleMsg struct{}
+
+// updateWindowTitle returns a command setting the --title in fullscreen mode,
+// followed by the time left rounded up to whole minutes. It is nil while the
+// title shown is unchanged, so the title is only written once a minute.
+func (m *model) updateWindowTitle() tea.Cmd {
+	if m.windowTitle == "" || !m.altscreen {
+		return nil
+	}
+	title := "toki: " + m.windowTitle
+	if !m.countUp {
+		left := (m.durations[m.state] - m.passed + time.Minute - 1).Truncate(time.Minute)
+		title += " – " + formatPresetDuration(left) + " remaining"
+	}
+	if title == m.shownTitle {
+		return nil
+	}
+	m.shownTitle = title
+	return tea.Raw(ansi.SetIconNameWindowTitle(title))
+}
+
+// segmentIndicator renders the position of the running segment, e.g.
+// "[2/4] ", for multi-segment timers.
+func (m model) segmentIndicator() string {
+	if m.noIndicator || m.timerCount() < 2 {
+		return ""
+	}
+	// During a delay this is the position of the timer it delays.
+	return fmt.Sprintf("[%d/%d] ", m.timersBefore(m.state)+1, m.timerCount())
+}
+
+// timerCount returns the number of segments that are not +offset delays.
+func (m model) timerCount() int {
+	return m.timersBefore(len(m.durations))
+}
+
+// timersBefore returns the number of segments before segment i that are not
+// +offset delays.
+func (m model) timersBefore(i int) int {
+	n := 0
+	for j := range i {
+		if j >= len(m.delays) || !m.delays[j] {
+			n++
+		}
+	}
+	return n
+}
+
+// quit returns the command exiting the program, clearing the terminal title
+// first if it was set.
+func (m model) quit() tea.Cmd {
+	if !m.titleProgress && (m.windowTitle == "" || !m.altscreen) {
+		return tea.Quit
+	}
+	return tea.Sequence(tea.Raw(ansi.SetIconNameWindowTitle("")), tea.Quit)
+}
+
+// sequence is tea.Sequence without the nil commands, which bubbletea only
+// skips at the top level and not in nested sequences.
+func sequence(cmds ...tea.Cmd) tea.Cmd {
+	var valid []tea.Cmd
+	for _, cmd := range cmds {
+		if cmd != nil {
+			valid = append(valid, cmd)
+		}
+	}
+	switch len(valid) {
+	case 0:
+		return nil
+	case 1:
+		return valid[0]
+	}
+	return tea.Sequence(valid...)
+}
+
+// ringBell returns a command writing the terminal bell, or nil when --bell
+// is not set.
+func (m model) ringBell() tea.Cmd {
+	if !m.bell {
+		return nil
+	}
+	return tea.Raw("\a")
+}
+
+func (m model) togglePause() (tea.Model, tea.Cmd) {
+	if !m.paused {
+		m.paused = true
+		m.pausedAt = time.Now()
+		return m, tea.Batch(m.timer.Toggle(), m.writeInstance())
+	}
+
+	m.paused = false
+	m.pausedFor += time.Since(m.pausedAt)
+	// A fresh timer is used instead of Toggle so that a tick still in flight
+	// from before the pause is rejected rather than doubling the tick rate.
+	m.timer = timer.New(m.timer.Timeout, timer.WithInterval(m.timer.Interval))
+	return m, tea.Batch(m.timer.Init(), m.writeInstance())
+}
+
 func (m model) View() string {
 	if m.quitting || m.interrupting {
 		return ""
 	}
 
-	var startTimeFormat string
-	switch strings.ToLower(m.startTimeFormat) {
-	case "24h":
-		startTimeFormat = "15:04" // See: https://golang.cafe/blog/golang-time-format-example.html
+	var result string
+	switch {
+	case m.showingDone:
+		result = "✅ " + boldStyle.Render(tr("done"))
+		if name := m.segmentName(); name != "" {
+			result = italicStyle.Render(name) + " " + boldStyle.Render(tr("finished"))
+		}
+	case m.waitingForStart && !m.startAt.IsZero():
+		at := m.startAt.In(m.location).Format(clockLayout(m.startTimeFormat))
+		left := max(time.Until(m.startAt), 0).Round(time.Second)
+		result = fmt.Sprintf(tr("waiting"), boldStyle.Render(at), boldStyle.Render(left.String()))
+	case m.waitingForStart:
+		result = fmt.Sprintf(tr("ready"), boldStyle.Render(tr("enter")))
+		if name := m.segmentName(); name != "" {
+			result += " " + italicStyle.Render(name)
+		}
+	case m.isDelay():
+		result = fmt.Sprintf(tr("starting"), boldStyle.Render(m.timerView()))
+	case m.large:
+		result = m.largeView()
 	default:
-		startTimeFormat = time.Kitchen
+		result = m.lineView()
+	}
+	if next := m.upcomingView(); next != "" && !m.isDelay() && !m.showingDone {
+		result += "\n" + next
+	}
+	if total := m.totalView(); total != "" && !m.showingDone {
+		result += "\n" + total
 	}
-	result := boldStyle.Render(m.start.Format(startTimeFormat))
-	if m.name != "" {
-		result += ": " + italicStyle.Render(m.name)
+	if m.paused && m.dimOnPause {
+		// The styles of the display would undo the faint, so they are dropped.
+		result = pausedStyle.Render("⏸ "+tr("paused")) + "\n" + faintStyle.Render(ansi.Strip(result))
+	}
+	if m.flashing() {
+		result = reverseStyle.Render(result)
 	}
-	endTime := m.start.Add(m.durations[m.state])
-	result += " - " + boldStyle.Render(endTime.Format(startTimeFormat)) +
-		" - " + boldStyle.Render(m.timer.View()) +
-		"\n" + m.progress.View()
 	if m.altscreen {
 		return altscreenStyle.
-			MarginTop((winHeight - 2) / 2).
+			MarginTop(altscreenMargin(result)).
 			Render(result)
 	}
 	return result
 }
 
+// altscreenMargin returns the top margin centering view on the screen, or 0
+// with --no-altscreen-margin.
+func altscreenMargin(view string) int {
+	if noAltMargin {
+		return 0
+	}
+	return (winHeight - lipgloss.Height(view)) / 2
+}
+
+// flashing reports whether the view is inverted for --flash. Once fewer than
+// --flash-threshold seconds are left it alternates every second.
+func (m model) flashing() bool {
+	if m.flashThreshold <= 0 || m.countUp || m.isDelay() {
+		return false
+	}
+	if m.timer.Timeout > time.Duration(m.flashThreshold)*time.Second {
+		return false
+	}
+	return int(m.passed/time.Second)%2 == 1
+}
+
+// lineView renders the label with the progress bar at the --label-position.
+func (m model) lineView() string {
+	label := m.prefix + m.labelView() + m.suffix
+	if m.noProgress {
+		return label
+	}
+	switch m.labelPosition {
+	case "bottom":
+		return m.barView() + "\n" + label
+	case "right":
+		return lipgloss.JoinHorizontal(lipgloss.Top, m.barView(), " ", label)
+	}
+	return label + "\n" + m.barView()
+}
+
+// labelView renders the start and end time, name and remaining time.
+func (m model) labelView() string {
+	startTimeFormat := clockLayout(m.startTimeFormat)
+	var head string
+	if !m.hideStartTime {
+		head = boldStyle.Render(m.start.In(m.location).Format(startTimeFormat))
+	}
+	if name := m.segmentName(); name != "" {
+		if head != "" {
+			head += ": "
+		}
+		head += italicStyle.Render(name)
+	}
+	var parts []string
+	if head != "" {
+		parts = append(parts, head)
+	}
+	if !m.countUp && !m.hideEndTime {
+		endTime := m.start.Add(m.durations[m.state] + m.pausedFor)
+		if m.paused {
+			endTime = endTime.Add(time.Since(m.pausedAt))
+		}
+		parts = append(parts, boldStyle.Render(endTime.In(m.location).Format(startTimeFormat)))
+	}
+	if m.overtimeRunning() {
+		parts = append(parts, overtimeStyle.Render("+"+m.overtimeView()))
+	} else {
+		parts = append(parts, boldStyle.Render(m.timerView()))
+	}
+	if m.wallclock {
+		parts = append(parts, time.Now().In(m.location).Format(startTimeFormat))
+	}
+	if m.paused && !m.dimOnPause {
+		parts = append(parts, boldStyle.Render(tr("paused")))
+	}
+	if delta := m.scheduleDelta(); delta != "" {
+		parts = append(parts, delta)
+	}
+	return m.segmentIndicator() + strings.Join(parts, " - ")
+}
+
+// scheduleDelta renders how far the session is behind (after pauses) or
+// ahead (after skips) of the planned schedule, e.g. "Δ +12s". It is only
+// shown for named multi-segment sessions that are off schedule.
+func (m model) scheduleDelta() string {
+	if len(m.durations) < 2 || len(m.names) == 0 {
+		return ""
+	}
+	// Delays only run before the first repeat.
+	var first, cycle, done time.Duration
+	for i, d := range m.durations {
+		delay := i < len(m.delays) && m.delays[i]
+		first += d
+		if !delay {
+			cycle += d
+		}
+		if i < m.state && (m.repeatCount == 0 || !delay) {
+			done += d
+		}
+	}
+	expected := done + m.passed
+	if m.repeatCount > 0 {
+		expected += first + cycle*time.Duration(m.repeatCount-1)
+	}
+	delta := (time.Since(m.sessionStart) - expected).Round(time.Second)
+	switch {
+	case delta > 0:
+		return "Δ +" + delta.String()
+	case delta < 0:
+		return "Δ " + delta.String()
+	}
+	return ""
+}
+
+// upcomingView renders the names and durations of the segments after the
+// running one, truncated to the terminal width. It is empty unless at least
+// two segments are left.
+func (m model) upcomingView() string {
+	var labels []string
+	for i := m.state + 1; i < len(m.durations); i++ {
+		if i < len(m.delays) && m.delays[i] {
+			continue
+		}
+		label := m.durations[i].String()
+		if name := m.nameOf(i); name != "" {
+			label = name + " " + label
+		}
+		if len(labels) > 0 {
+			label = "  " + label
+		}
+		labels = append(labels, faintStyle.Render(label))
+	}
+	if len(labels) < 2 {
+		return ""
+	}
+	row := tr("next") + " " + lipgloss.JoinHorizontal(lipgloss.Top, labels...)
+	if width := m.width - padding*2; m.width > 0 && lipgloss.Width(row) > width {
+		row = ansi.Truncate(row, width, "…")
+	}
+	return row
+}
+
+// barView renders the progress bar followed by the sparkline, and the
+// session progress bar below it, if enabled.
+func (m model) barView() string {
+	bar := m.progress.View()
+	if m.showSpark {
+		bar += " " + m.spark.View()
+	}
+	if m.showTotalBar() {
+		bar += "\n" + m.totalBar.View()
+	}
+	return bar
+}
+
+func (m model) showTotalBar() bool {
+	return !m.noTotalBar && !m.noProgress && m.timerCount() > 1
+}
+
+// totalPercent returns the progress through all segments of the session.
+func (m model) totalPercent() float64 {
+	var done, total time.Duration
+	for i, d := range m.durations {
+		if i < m.state {
+			done += d
+		}
+		total += d
+	}
+	return float64(done+m.passed) / float64(total)
+}
+
+// totalView renders the time elapsed and remaining across all segments. It
+// is empty for single segment timers, not counting delays, or with --no-total.
+func (m model) totalView() string {
+	if m.noTotal || m.timerCount() < 2 {
+		return ""
+	}
+	var done, left time.Duration
+	for i, d := range m.durations {
+		if i < m.state {
+			done += d
+		} else {
+			left += d
+		}
+	}
+	done += m.passed
+	left -= m.passed
+	return fmt.Sprintf(tr("total"), boldStyle.Render(done.Round(time.Second).String()),
+		boldStyle.Render(max(left, 0).Round(time.Second).String()))
+}
+
 var (
 	name            string
 	repeat          int
 	altscreen       bool
+	bell            bool
+	large           bool
+	noProgress      bool
+	barWidth        int
+	noUrgencyColors bool
+	reverse         bool
+	elapsed         bool
+	countUp         bool
+	randomOrder     bool
+	overtime        bool
+	confirm         bool
+	startAt         string
+	flash           bool
+	flashThreshold  int
+	titleProgress   bool
+	noTotal         bool
+	noIndicator     bool
+	noTotalBar      bool
+	dimOnPause      bool
+	hideStartTime   bool
+	hideEndTime     bool
+	wallclock       bool
+	showSpark       bool
+	sparkWidth      int
+	maxWidth        int
+	separator       string
+	padding         int
+	tickInterval    time.Duration
+	speed           int
+	afterDuration   time.Duration
+	grace           time.Duration
 	startTimeFormat string
+	timezone        string
+	labelPosition   string
+	prefix          string
+	suffix          string
+	timerFormat     string
+	execCommand     string
+	execOnInterrupt bool
+	onStart         string
+	onSegmentStart  string
+	segmentNames    []string   // set by toki run
+	segmentExecs    []string   // set by toki run
+	segmentColors   []barColor // set by toki run
+	onPct           []string
+	keybinds        []string
+	tmuxPane        string
+	tmuxFormat      string
+	logPath         string
+	tags            map[string]string
+	stateFile       string
+	soundFile       string
+	notifyDesktop   bool
+	useSyslog       bool
+	appendTo        string
+	progressStyle   string
+	beepInterval    time.Duration
+	hideProgressPct bool
+	pipePath        string
+	countdownFormat string
+	dryRun          bool
+	randomJitter    float64
+	verbose         bool
+	windowTitle     string
+	locale          string
+	silent          bool
+	outputFormat    string
+	webhookURL      string
+	webhookUser     string
+	webhookPass     string
+	endAt           string
+	pomodoro        bool
+	pomodoroLong    int
+	colorTheme      string
+	themeName       string
 	winHeight       int
+	noAltMargin     bool
 	version         = "dev"
 	quitKeys        = key.NewBinding(key.WithKeys("esc", "q"))
 	intKeys         = key.NewBinding(key.WithKeys("ctrl+c"))
-	altscreenStyle  = lipgloss.NewStyle().MarginLeft(padding)
+	pauseKeys       = key.NewBinding(key.WithKeys("space", "p"))
+	skipKeys        = key.NewBinding(key.WithKeys("n", "right"))
+	restartKeys     = key.NewBinding(key.WithKeys("r"))
+	altscreenStyle  = lipgloss.NewStyle()
 	boldStyle       = lipgloss.NewStyle().Bold(true)
 	italicStyle     = lipgloss.NewStyle().Italic(true)
+	faintStyle      = lipgloss.NewStyle().Faint(true)
+	overtimeStyle   = lipgloss.NewStyle().Bold(true)
+	reverseStyle    = lipgloss.NewStyle().Reverse(true)
+	pausedStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("3")).Padding(0, 1)
 )
 
-const (
-	padding  = 2
-	maxWidth = 80
-)
+// ExitError makes toki exit with Code: 0 when the timer completed, 1 when
+// it was interrupted with ctrl+c, 2 when it was quit with q or esc and 3 when
+// the exec hook failed. Err is printed when set.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// countUpTimeout is the timer duration of --count-up, long enough to never
+// run out.
+const countUpTimeout = 100 * 365 * 24 * time.Hour
 
 var rootCmd = &cobra.Command{
-	Use:          "toki",
-	Short:        "A timer with many features",
-	Version:      version,
-	SilenceUsage: true,
-	Args:         cobra.ExactArgs(1),
+	Use:           "toki",
+	Short:         "A timer with many features",
+	Version:       version,
+	SilenceUsage:  true,
+	SilenceErrors: true, // printed by main, which also picks the exit code
+	Args:          cobra.MaximumNArgs(1),
+	PersistentPreRunE: func(*cobra.Command, []string) error {
+		level := slog.LevelInfo
+		out := io.Discard
+		if verbose {
+			level, out = slog.LevelDebug, os.Stderr
+		}
+		slog.SetDefault(slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: level})))
+		return loadLocale(locale)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		timerStringArray := splitTimerArgString(args[0])
-
-		var durations []time.Duration
-		for index, item := range timerStringArray {
-			timerStringArray[index] = addSuffixIfArgIsNumber(item, "s")
+		var opts []tea.ProgramOption
+		var timerStringArray []string
+		sep, err := regexp.Compile(separator)
+		if err != nil {
+			return fmt.Errorf("invalid separator: %w", err)
+		}
+		switch {
+		case countUp:
+			if len(args) > 0 {
+				return fmt.Errorf("--count-up does not take a duration argument")
+			}
+			timerStringArray = []string{countUpTimeout.String()}
+		case pomodoro:
+			if len(args) > 0 {
+				return fmt.Errorf("--pomodoro does not take a duration argument")
+			}
+			var names []string
+			timerStringArray, names = pomodoroSegments(pomodoroLong)
+			if name == "" {
+				name = strings.Join(names, ",")
+			}
+		case endAt != "":
+			if len(args) > 0 {
+				return fmt.Errorf("--end does not take a duration argument")
+			}
+			d, err := untilClock(endAt, startTimeFormat)
+			if err != nil {
+				return err
+			}
+			timerStringArray = []string{d.String()}
+		case len(args) == 0:
+			timerStringArray = splitTimerArgString(defaultDuration(), sep)
+		case args[0] == "-":
+			var err error
+			timerStringArray, err = readTimerArgs(os.Stdin)
+			if err != nil {
+				return err
+			}
+			// stdin is taken by the durations, read keys from the terminal.
+			opts = append(opts, tea.WithInputTTY())
+		default:
+			timerStringArray = splitTimerArgString(expandPreset(args[0]), sep)
+		}
 
-			duration, err := time.ParseDuration(timerStringArray[index])
+		timerStringArray, delays := splitDelays(timerStringArray)
+		durations, err := parseDurations(timerStringArray)
+		if err != nil {
+			return err
+		}
+		if len(durations) == 0 {
+			return fmt.Errorf("no durations given")
+		}
+		names := segmentNames
+		if names == nil {
+			names = splitNames(name)
+		}
+		if randomOrder && len(durations) > 1 {
+			names = shuffleSegments(durations, delays, names)
+			order := formatDurations(durations)
+			if len(names) > 1 {
+				for i, n := range names {
+					if n != "" && i < len(order) {
+						order[i] += " " + n
+					}
+				}
+			}
+			cmd.Printf("Order: %s\n", strings.Join(order, ", "))
+		}
+		if randomJitter != 0 {
+			if randomJitter < 0 || randomJitter >= 100 {
+				return fmt.Errorf("--random-jitter must be between 0 and 100")
+			}
+			jitterSegments(durations, delays, randomJitter)
+		}
+		if dryRun {
+			plan := model{durations: durations, delays: delays, names: names, repeat: repeat}
+			return plan.writePlan(cmd.OutOrStdout())
+		}
+		switch labelPosition {
+		case "top", "bottom", "right":
+		default:
+			return fmt.Errorf("unknown label position %q, expected top, bottom or right", labelPosition)
+		}
+		switch outputFormat {
+		case "", "json", "tsv":
+		default:
+			return fmt.Errorf("unknown output format %q, expected json or tsv", outputFormat)
+		}
+		if _, ok := progressStyles[progressStyle]; !ok {
+			return fmt.Errorf("unknown progress style %q, expected full, bar, dots or circle", progressStyle)
+		}
+		switch strings.ToLower(timerFormat) {
+		case "", "hms", "seconds", "colon":
+		default:
+			return fmt.Errorf("unknown timer format %q", timerFormat)
+		}
+		theme, err := loadTheme(themeName)
+		if err != nil {
+			return err
+		}
+		boldStyle, italicStyle = theme.Bold, theme.Italic
+		overtimeStyle = boldStyle.Foreground(lipgloss.Color(cmp.Or(theme.Critical.to, theme.Critical.from)))
+		if noColor() {
+			boldStyle, italicStyle = lipgloss.NewStyle(), lipgloss.NewStyle()
+			faintStyle, overtimeStyle = lipgloss.NewStyle(), lipgloss.NewStyle()
+			pausedStyle = lipgloss.NewStyle()
+			opts = append(opts, tea.WithColorProfile(colorprofile.Ascii))
+		}
+		// Themes bring their own colors, --color auto only replaces the
+		// default ones once the terminal reported its background.
+		autoColor := strings.ToLower(colorTheme) == "auto" && strings.ToLower(themeName) == "default" && !noColor()
+		if c := strings.ToLower(colorTheme); c != "" && c != "default" && c != "auto" {
+			if theme.Progress, err = progressColor(colorTheme); err != nil {
+				return err
+			}
+		}
+		var pctHooks []pctHook
+		for _, v := range onPct {
+			h, err := parsePctHook(v)
 			if err != nil {
 				return err
 			}
-			durations = append(durations, duration)
+			pctHooks = append(pctHooks, h)
 		}
-		var opts []tea.ProgramOption
+		tmuxTemplate, err := template.New("tmux").Parse(tmuxFormat)
+		if err != nil {
+			return fmt.Errorf("invalid tmux format: %w", err)
+		}
+		var countdownTemplate *template.Template
+		if countdownFormat != "" {
+			if countdownTemplate, err = template.New("countdown").Parse(countdownFormat); err != nil {
+				return fmt.Errorf("invalid countdown format: %w", err)
+			}
+		}
+		location := time.Local
+		if timezone != "" {
+			if location, err = time.LoadLocation(timezone); err != nil {
+				return fmt.Errorf("invalid timezone %q", timezone)
+			}
+		}
+
+		if padding < 0 {
+			return fmt.Errorf("--padding must not be negative")
+		}
+		if speed < 1 {
+			return fmt.Errorf("--speed must be at least 1")
+		}
+		altscreenStyle = altscreenStyle.MarginLeft(padding)
 		if altscreen {
 			opts = append(opts, tea.WithAltScreen())
 		}
+		urgency := urgencyConfig{Warning: 75, Critical: 90}
+		if err := configSection("urgency", &urgency); err != nil {
+			return err
+		}
+		if err := loadKeybindings(keybinds); err != nil {
+			return err
+		}
+		if barWidth == 0 {
+			if env := os.Getenv("TOKI_WIDTH"); env != "" {
+				if barWidth, err = strconv.Atoi(env); err != nil {
+					return fmt.Errorf("invalid TOKI_WIDTH %q", env)
+				}
+			}
+		}
+		var bar urgencyBar
+		if !noProgress {
+			bar = newUrgencyBar(theme, progressStyles[progressStyle], reverse, !noUrgencyColors && !countUp, urgency)
+			if hideProgressPct {
+				progress.WithoutPercentage()(&bar.Model)
+			}
+			if barWidth > 0 {
+				bar.SetWidth(barWidth)
+			}
+		}
+		totalBar := newTotalBar(bar.Width())
 		interval := timerInterval(durations[0])
-		repeat-- // remove one because will be launched once on start
-		m, err := tea.NewProgram(model{
+		if tickInterval != 0 {
+			var total time.Duration
+			for _, d := range durations {
+				total += d
+			}
+			if tickInterval < time.Millisecond || tickInterval > total {
+				return fmt.Errorf("--interval must be between 1ms and the total duration %s", total)
+			}
+			interval = tickInterval
+		}
+		instanceFile := instancePath(os.Getpid())
+		defer os.Remove(instanceFile)
+
+		startedAt := time.Now()
+		initial := model{
 			durations:       durations,
+			delays:          delays,
+			interval:        tickInterval,
+			speed:           speed,
 			state:           0,
 			timer:           timer.New(durations[0], timer.WithInterval(interval)),
-			progress:        progress.New(progress.WithDefaultGradient()),
-			name:            name,
+			progress:        bar,
+			totalBar:        totalBar,
+			noTotalBar:      noTotalBar,
+			autoColor:       autoColor && !noProgress,
+			dimOnPause:      dimOnPause,
+			hideStartTime:   hideStartTime,
+			hideEndTime:     hideEndTime,
+			wallclock:       wallclock,
+			noProgress:      noProgress,
+			fixedWidth:      barWidth > 0,
+			elapsed:         elapsed || countUp,
+			countUp:         countUp,
+			overtime:        overtime,
+			after:           afterDuration,
+			grace:           grace,
+			waitingForStart: confirm || startAt != "",
+			titleProgress:   titleProgress,
+			windowTitle:     windowTitle,
+			noTotal:         noTotal,
+			noIndicator:     noIndicator,
+			showSpark:       showSpark,
+			spark:           newSparkline(sparkWidth),
+			instanceFile:    instanceFile,
+			stateFile:       stateFile,
+			onStart:         onStart,
+			onSegmentStart:  onSegmentStart,
+			segmentExecs:    segmentExecs,
+			segmentColors:   segmentColors,
+			baseColor:       theme.Progress,
+			pctHooks:        pctHooks,
+			tmuxPane:        tmuxPane,
+			tmuxFormat:      tmuxTemplate,
+			countdownFormat: countdownTemplate,
+			names:           names,
 			repeat:          repeat,
 			altscreen:       altscreen,
+			bell:            bell,
+			beepInterval:    beepInterval,
+			large:           large,
 			startTimeFormat: startTimeFormat,
-			start:           time.Now(),
-		}, opts...).Run()
+			location:        location,
+			labelPosition:   labelPosition,
+			prefix:          prefix,
+			suffix:          suffix,
+			timerFormat:     strings.ToLower(timerFormat),
+			start:           startedAt,
+			sessionStart:    startedAt,
+		}
+		if flash {
+			initial.flashThreshold = flashThreshold
+		}
+		if !noProgress && len(segmentColors) > 0 {
+			initial.progress.SetBaseColor(initial.segmentColor())
+		}
+		if startAt != "" {
+			d, err := untilClock(startAt, startTimeFormat)
+			if err != nil {
+				return err
+			}
+			initial.startAt = time.Now().Add(d)
+		}
+		if silent || outputFormat != "" {
+			time.Sleep(time.Until(initial.startAt))
+			startedAt = time.Now()
+		}
+		if silent {
+			count := initial.runSilent()
+			time.Sleep(afterDuration)
+			return finish(cmd, durations, startedAt, count)
+		}
+		if outputFormat != "" {
+			count := initial.runOutput(os.Stdout, outputFormat)
+			time.Sleep(afterDuration)
+			return finish(cmd, durations, startedAt, count)
+		}
+		if stateFile != "" {
+			if err := initial.saveState(); err != nil {
+				return err
+			}
+		}
+		if pipePath != "" {
+			pipe, closePipe, err := openPipe(pipePath)
+			if err != nil {
+				return err
+			}
+			defer closePipe()
+			initial.pipe = pipe
+		}
+		opts = append(opts, tea.WithoutSignalHandler()) // see forwardSignals
+		p := tea.NewProgram(initial, opts...)
+		if stop, err := serveControl(p); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not open the control socket: %v\n", err)
+		} else {
+			defer stop()
+		}
+		stopSignals := forwardSignals(p)
+		m, err := p.Run()
+		stopSignals()
 		if err != nil {
 			return err
 		}
 		if m.(model).interrupting {
-			return fmt.Errorf("interrupted")
+			if execOnInterrupt && execCommand != "" {
+				if hook := startHook(execCommand, name); hook != nil {
+					if err := hook.Wait(); err != nil {
+						fmt.Fprintf(os.Stderr, "warning: exec hook failed: %v\n", err)
+					}
+				}
+			}
+			if m.(model).terminated {
+				return &ExitError{Code: 1, Err: errors.New("terminated")}
+			}
+			return &ExitError{Code: 1, Err: errors.New("interrupted")}
 		}
-		if name != "" {
-			cmd.Printf("%s ", name)
+		if countUp {
+			cmd.Printf(tr("elapsed")+"\n", m.(model).passed.Round(time.Second))
+			return nil
 		}
-		cmd.Printf("finished!\n")
-		return nil
+		if !m.(model).completed {
+			return &ExitError{Code: 2}
+		}
+		return finish(cmd, durations, m.(model).sessionStart, m.(model).repeatCount)
 	},
 }
 
+// newTotalBar returns the thin bar showing the progress of the whole
+// sequence below the segment bar.
+func newTotalBar(width int) progress.Model {
+	bar := progress.New(
+		progress.WithSolidFill(lipgloss.Color("#606060")),
+		progress.WithFillCharacters('━', '─'),
+		progress.WithWidth(width),
+	)
+	bar.EmptyColor = lipgloss.Color("#3A3A3A")
+	return bar
+}
+
+// jitterSegments varies the duration of each segment other than delays by up
+// to pct percent either way for --random-jitter.
+func jitterSegments(durations []time.Duration, delays []bool, pct float64) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i, d := range durations {
+		if delays[i] {
+			continue
+		}
+		factor := 1 + (rng.Float64()*2-1)*pct/100
+		jittered := time.Duration(float64(d) * factor)
+		if jittered >= time.Second {
+			jittered = jittered.Round(time.Second)
+		}
+		durations[i] = max(jittered, time.Millisecond)
+	}
+}
+
+// shuffleSegments shuffles the segments for --random-order, keeping their
+// names, exec_on_complete hooks and colors with them, and returns the names
+// in the new order. Delays stay where they are.
+func shuffleSegments(durations []time.Duration, delays []bool, names []string) []string {
+	names = slices.Clone(names)
+	for len(names) > 1 && len(names) < len(durations) {
+		names = append(names, "")
+	}
+	var idx []int
+	for i := range durations {
+		if !delays[i] {
+			idx = append(idx, i)
+		}
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rng.Shuffle(len(idx), func(a, b int) {
+		i, j := idx[a], idx[b]
+		durations[i], durations[j] = durations[j], durations[i]
+		if len(names) > 1 {
+			names[i], names[j] = names[j], names[i]
+		}
+		if len(segmentExecs) == len(durations) {
+			segmentExecs[i], segmentExecs[j] = segmentExecs[j], segmentExecs[i]
+		}
+		if len(segmentColors) == len(durations) {
+			segmentColors[i], segmentColors[j] = segmentColors[j], segmentColors[i]
+		}
+	})
+	return names
+}
+
+// runSilent blocks for the durations of every repeat without any output and
+// returns the number of completed runs.
+func (m model) runSilent() int {
+	count := 0
+	for m.repeat < 0 || count < max(m.repeat, 1) {
+		for i, d := range m.durations {
+			if count > 0 && i < m.repeatStart() {
+				continue
+			}
+			time.Sleep(d / time.Duration(m.speed))
+			m.state = i
+			m.completeSegment()
+		}
+		count++
+	}
+	return count
+}
+
+// completeSegment runs the exec_on_complete hook of the running segment and
+// waits for it, for the modes without the TUI.
+func (m model) completeSegment() {
+	if hook := m.runHook(m.segmentExec()); hook != nil {
+		hook()
+	}
+}
+
+// progressLine is printed on every tick with --output json.
+type progressLine struct {
+	ElapsedMS int64   `json:"elapsed_ms"`
+	TotalMS   int64   `json:"total_ms"`
+	Pct       float64 `json:"pct"`
+	Name      string  `json:"name"`
+	State     int     `json:"state"`
+}
+
+// runOutput runs the timer without the TUI, writing the progress of the
+// running segment to w on every tick in the given format, and returns the
+// number of completed runs.
+func (m model) runOutput(w io.Writer, format string) int {
+	ticker := time.NewTicker(m.timer.Interval)
+	defer ticker.Stop()
+	enc := json.NewEncoder(w)
+	count := 0
+	for m.repeat < 0 || count < max(m.repeat, 1) {
+		for i, d := range m.durations {
+			if count > 0 && i < m.repeatStart() {
+				continue
+			}
+			for passed := time.Duration(0); passed < d; {
+				<-ticker.C
+				passed = min(passed+m.timer.Interval*time.Duration(m.speed), d)
+				line := progressLine{
+					ElapsedMS: passed.Milliseconds(),
+					TotalMS:   d.Milliseconds(),
+					Pct:       float64(passed) / float64(d),
+					Name:      m.nameOf(i),
+					State:     i,
+				}
+				if format == "tsv" {
+					fmt.Fprintf(w, "%d\t%d\t%.3f\t%s\t%d\n", line.ElapsedMS, line.TotalMS, line.Pct, line.Name, line.State)
+				} else {
+					_ = enc.Encode(line)
+				}
+			}
+			m.state = i
+			m.completeSegment()
+		}
+		count++
+	}
+	return count
+}
+
+// finish runs everything that happens once a timer completed normally: the
+// history log, hooks, notifications and the final message.
+func finish(cmd *cobra.Command, durations []time.Duration, startedAt time.Time, count int) error {
+	if logPath != "" {
+		err := appendHistory(logPath, historyRecord{
+			Name:       name,
+			Durations:  formatDurations(durations),
+			StartedAt:  startedAt,
+			FinishedAt: time.Now(),
+			Tags:       tags,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not write history: %v\n", err)
+		}
+	}
+	if appendTo != "" {
+		var total time.Duration
+		for _, d := range durations {
+			total += d
+		}
+		if err := appendLogLine(appendTo, name, total*time.Duration(max(count, 1)), time.Now()); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not write to %s: %v\n", appendTo, err)
+		}
+	}
+	if useSyslog {
+		timerName := cmp.Or(name, "timer")
+		msg := fmt.Sprintf("%s completed in %s", timerName, time.Since(startedAt).Round(time.Second))
+		if err := writeSyslog(msg); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not write to syslog: %v\n", err)
+		}
+	}
+	var hook *exec.Cmd
+	if execCommand != "" {
+		hook = startHook(execCommand, name)
+	}
+	if webhookURL != "" {
+		var total time.Duration
+		for _, d := range durations {
+			total += d
+		}
+		err := postWebhook(webhookURL, webhookUser, webhookPass, webhookPayload{
+			Name:       name,
+			Duration:   (total * time.Duration(max(count, 1))).String(),
+			FinishedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: webhook failed: %v\n", err)
+		}
+	}
+	if notifyDesktop {
+		title := name
+		if title == "" {
+			title = "toki"
+		}
+		if err := notify(title, tr("timer_finished")); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not send notification: %v\n", err)
+		}
+	}
+	switch {
+	case soundFile != "":
+		if err := playSound(soundFile); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not play sound: %v\n", err)
+		}
+	case bell:
+		_ = playBeep() // best effort, unlike an explicit --sound
+	}
+	if name != "" {
+		cmd.Printf("%s ", name)
+	}
+	if count > 1 {
+		cmd.Printf(tr("finished_times")+"\n", count)
+	} else {
+		cmd.Println(tr("finished"))
+	}
+	if hook != nil {
+		if err := hook.Wait(); err != nil {
+			return &ExitError{Code: 3, Err: fmt.Errorf("exec hook failed: %w", err)}
+		}
+	}
+	return nil
+}
+
 var manCmd = &cobra.Command{
 	Use:                   "man",
 	Short:                 "Generates man pages",
@@ -221,17 +1673,121 @@ var manCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&name, "name", "n", "", "timer name(s)")
+	rootCmd.Flags().StringVarP(&separator, "separator", "", TIMER_ARG_SEP, "regular expression splitting the durations of the argument")
+	rootCmd.Flags().StringVarP(&name, "name", "n", "", "timer name, or comma separated names for each segment (leave one empty for an unnamed segment)")
 	rootCmd.Flags().IntVarP(&repeat, "repeat", "r", 1, "timer repeat number (-1 for infinite)")
 	rootCmd.Flags().BoolVarP(&altscreen, "fullscreen", "f", false, "fullscreen")
+	rootCmd.Flags().BoolVarP(&noAltMargin, "no-altscreen-margin", "", false, "show the fullscreen timer at the top instead of centered")
+	rootCmd.Flags().BoolVarP(&large, "large", "l", false, "display the remaining time with large digits")
+	rootCmd.Flags().BoolVarP(&noProgress, "no-progress", "", false, "hide the progress bar")
+	rootCmd.Flags().IntVarP(&barWidth, "width", "w", 0, "fixed progress bar width instead of following the terminal ($TOKI_WIDTH)")
+	rootCmd.Flags().IntVarP(&padding, "padding", "", 2, "fullscreen left margin, also kept free on both sides of the progress bar")
+	rootCmd.Flags().IntVarP(&maxWidth, "max-width", "", 80, "maximum progress bar width outside of fullscreen, 0 for unlimited")
+	rootCmd.Flags().BoolVarP(&noUrgencyColors, "no-urgency-colors", "", false, "keep the progress bar color as time runs out")
+	rootCmd.Flags().BoolVarP(&reverse, "reverse", "", false, "start with a full progress bar and drain it")
+	rootCmd.Flags().BoolVarP(&elapsed, "elapsed", "", false, "show elapsed time instead of remaining time")
+	rootCmd.Flags().IntVarP(&speed, "speed", "", 1, "run the timer this many times faster, for trying out long sequences")
+	rootCmd.Flags().DurationVarP(&tickInterval, "interval", "", 0, "override the tick rate, e.g. 500ms (default 100ms below a minute, 1s otherwise)")
+	rootCmd.Flags().BoolVarP(&titleProgress, "title-progress", "", false, "show the progress in the terminal title")
+	rootCmd.Flags().StringVarP(&windowTitle, "title", "", "", "set the terminal title to this text and the minutes left in fullscreen mode")
+	rootCmd.Flags().BoolVarP(&flash, "flash", "", false, "flash the display when the timer is almost up")
+	rootCmd.Flags().IntVarP(&flashThreshold, "flash-threshold", "", 10, "seconds left at which --flash starts, 0 to disable")
+	rootCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "print the parsed segments and their total instead of running the timer")
+	rootCmd.Flags().Float64VarP(&randomJitter, "random-jitter", "", 0, "vary each segment duration randomly by up to this percentage either way")
+	rootCmd.Flags().BoolVarP(&randomOrder, "random-order", "", false, "shuffle the segments before starting")
+	rootCmd.Flags().BoolVarP(&confirm, "confirm", "", false, "wait for Enter before starting the timer")
+	rootCmd.Flags().StringVarP(&startAt, "start-at", "", "", "wait until a wall-clock time before starting the timer, e.g. 14:00 or 2:00PM")
+	rootCmd.Flags().BoolVarP(&overtime, "overtime", "", false, "keep counting past the end in red until quit")
+	rootCmd.Flags().DurationVarP(&grace, "grace", "", 0, "show a done message for this long before quitting, e.g. 2s")
+	rootCmd.Flags().DurationVarP(&afterDuration, "after", "", 0, "wait this long after the last segment before finishing, e.g. 30s")
+	rootCmd.Flags().BoolVarP(&countUp, "count-up", "", false, "count up from zero until quit, like a stopwatch")
+	rootCmd.Flags().BoolVarP(&noIndicator, "no-segment-indicator", "", false, "hide the [n/total] segment indicator of multi-segment timers")
+	rootCmd.Flags().BoolVarP(&noTotal, "no-total", "", false, "hide the total elapsed and remaining time of multi-segment timers")
+	rootCmd.Flags().BoolVarP(&silent, "silent", "s", false, "run without any display and only print when finished")
+	rootCmd.Flags().StringVarP(&outputFormat, "output", "", "", "print progress on every tick instead of the display, possible values: json, tsv")
+	rootCmd.Flags().BoolVarP(&noTotalBar, "no-total-bar", "", false, "hide the session progress bar of multi-segment timers")
+	rootCmd.Flags().BoolVarP(&hideStartTime, "hide-start-time", "", false, "hide the start time of the segment")
+	rootCmd.Flags().BoolVarP(&hideEndTime, "hide-end-time", "", false, "hide the end time of the segment")
+	rootCmd.Flags().BoolVarP(&dimOnPause, "dim-on-pause", "", false, "grey out the display and show a badge while paused")
+	rootCmd.Flags().BoolVarP(&showSpark, "sparkline", "", false, "show a sparkline of recent progress next to the progress bar")
+	rootCmd.Flags().IntVarP(&sparkWidth, "sparkline-width", "", 20, "number of ticks shown in the sparkline")
+	rootCmd.Flags().BoolVarP(&bell, "bell", "b", false, "ring the terminal bell when a timer ends")
+	rootCmd.Flags().DurationVarP(&beepInterval, "beep-interval", "", 0, "ring the terminal bell every interval while a timer runs")
 	rootCmd.Flags().StringVarP(&startTimeFormat, "format", "", "", "Specify start time format, possible values: 24h, kitchen")
+	rootCmd.Flags().StringVarP(&startTimeFormat, "clock-format", "", "", "alias for --format, which also applies to --wallclock")
+	rootCmd.Flags().StringVarP(&pipePath, "pipe", "", "", "create a named pipe at this path to read stop, pause, resume, skip and restart commands from")
+	rootCmd.Flags().BoolVarP(&wallclock, "wallclock", "", false, "show the current time next to the timer")
+	rootCmd.Flags().StringVarP(&labelPosition, "label-position", "", "top", "position of the timer readout relative to the progress bar, possible values: top, bottom, right")
+	rootCmd.Flags().StringVarP(&prefix, "prefix", "", "", "text shown before the timer line")
+	rootCmd.Flags().StringVarP(&suffix, "suffix", "", "", "text shown after the timer line")
+	rootCmd.Flags().StringVarP(&timezone, "timezone", "", "", "display start and end times in this timezone, e.g. America/New_York")
+	rootCmd.Flags().BoolVarP(&hideProgressPct, "hide-progress-pct", "", false, "hide the percentage next to the progress bar")
+	rootCmd.Flags().StringVarP(&progressStyle, "progress-style", "", "full", "look of the progress bar, possible values: full, bar, dots, circle")
+	rootCmd.Flags().StringVarP(&timerFormat, "timer-format", "", "", "Specify countdown format, possible values: hms, seconds, colon")
+	rootCmd.Flags().StringVarP(&countdownFormat, "countdown-format", "", "", "Go template of the countdown, with the .H, .M and .S of the remaining time")
+	rootCmd.PersistentFlags().StringVarP(&locale, "locale", "", "", "language of the display: en, de, fr or ja (default from LANG)")
+	rootCmd.Flags().StringToStringVarP(&tags, "tag", "", nil, "metadata added to the history record, as key=value (repeatable)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "", false, "log every tick and segment change to stderr")
+	rootCmd.PersistentFlags().StringVarP(&logPath, "log", "", defaultHistoryPath(), "history file completed timers are logged to (empty to disable)")
+	rootCmd.Flags().StringVarP(&endAt, "end", "", "", "run until the given time of day instead of for a duration, e.g. 17:00")
+	rootCmd.Flags().BoolVarP(&pomodoro, "pomodoro", "p", false, "run a pomodoro sequence of 25m work blocks and 5m breaks")
+	rootCmd.Flags().IntVarP(&pomodoroLong, "pomodoro-long", "", 4, "number of pomodoro work blocks before the 15m long break")
+	rootCmd.Flags().StringVarP(&colorTheme, "color", "c", "auto", "progress bar color, possible values: auto (based on the terminal background), default, green, blue, red, rainbow, none")
+	rootCmd.Flags().StringVarP(&themeName, "theme", "t", "default", "display theme: default, dracula, solarized, nord, gruvbox or a file in the config themes directory")
+	rootCmd.Flags().StringVarP(&stateFile, "state-file", "", "", "file the timer progress is written to as JSON on every tick")
+	rootCmd.Flags().BoolVarP(&notifyDesktop, "notify", "", false, "show a desktop notification when the timer finishes")
+	rootCmd.Flags().StringVarP(&appendTo, "append-to", "", "", "append a line for each completed timer to this text file")
+	rootCmd.Flags().BoolVarP(&useSyslog, "syslog", "", false, "log the completion of the timer to syslog")
+	rootCmd.Flags().StringVarP(&webhookURL, "webhook", "", "", "URL a JSON payload is POSTed to when the timer finishes")
+	rootCmd.Flags().StringVarP(&webhookUser, "webhook-user", "", "", "basic auth user for --webhook")
+	rootCmd.Flags().StringVarP(&webhookPass, "webhook-pass", "", "", "basic auth password for --webhook")
+	rootCmd.Flags().StringVarP(&soundFile, "sound", "", "", "audio file to play when the timer finishes")
+	rootCmd.Flags().StringVarP(&execCommand, "exec", "e", "", "shell command to run on completion (%n is replaced by the timer name)")
+	rootCmd.Flags().BoolVarP(&execOnInterrupt, "exec-on-interrupt", "", false, "also run the --exec command when the timer is interrupted or terminated")
+	rootCmd.Flags().StringVarP(&onStart, "on-start", "", "", "shell command to run when the timer starts (%n is replaced by the segment name)")
+	rootCmd.Flags().StringArrayVarP(&keybinds, "keybind", "", nil, "rebind the keys of an action, as action=key1,key2, e.g. quit=x,esc (repeatable)")
+	rootCmd.Flags().StringArrayVarP(&onPct, "on-pct", "", nil, "shell command to run when each segment reaches a percentage, as pct:command (repeatable)")
+	rootCmd.Flags().StringVarP(&tmuxPane, "tmux-pane", "", "", "set the @toki_progress option of this tmux pane on every tick")
+	rootCmd.Flags().StringVarP(&tmuxFormat, "tmux-format", "", "{{.Name}} {{.Remaining}}", "Go template of the --tmux-pane status, with .Name, .Remaining, .Elapsed and .Percent")
+	rootCmd.Flags().StringVarP(&onSegmentStart, "on-segment-start", "", "", "shell command to run when each segment starts (%n and %i are replaced by the segment name and index)")
+
+	rootCmd.MarkFlagsMutuallyExclusive("silent", "fullscreen")
+	rootCmd.MarkFlagsMutuallyExclusive("count-up", "silent")
+	rootCmd.MarkFlagsMutuallyExclusive("after", "overtime")
+	rootCmd.MarkFlagsMutuallyExclusive("grace", "overtime")
+	rootCmd.MarkFlagsMutuallyExclusive("output", "silent", "fullscreen")
+	rootCmd.MarkFlagsMutuallyExclusive("confirm", "silent", "output")
+	rootCmd.MarkFlagsMutuallyExclusive("start-at", "confirm")
+	rootCmd.MarkFlagsMutuallyExclusive("start-at", "end")
+	rootCmd.MarkFlagsMutuallyExclusive("countdown-format", "timer-format")
+	rootCmd.MarkFlagsMutuallyExclusive("title", "title-progress")
 
 	rootCmd.AddCommand(manCmd)
+
+	runCmd.Flags().StringVarP(&timerFile, "file", "", "", "YAML file listing the segments to run")
+	_ = runCmd.MarkFlagRequired("file")
+	runCmd.Flags().AddFlagSet(rootCmd.Flags())
+	rootCmd.AddCommand(runCmd)
 }
 
 func main() {
+	if err := loadConfig(defaultConfigPath()); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not load config: %v\n", err)
+	}
+	if noColor() {
+		// The timer itself applies NO_COLOR after loading its theme.
+		boldStyle, italicStyle, faintStyle = lipgloss.NewStyle(), lipgloss.NewStyle(), lipgloss.NewStyle()
+	}
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		code := 1
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.Code
+		}
+		if msg := err.Error(); msg != "" {
+			fmt.Fprintln(os.Stderr, "Error:", msg)
+		}
+		os.Exit(code)
 	}
 }
 
@@ -243,17 +1799,298 @@ func timerInterval(d time.Duration) time.Duration {
 	return time.Second
 }
 
+// pomodoroSegments returns the durations and names of a pomodoro sequence of
+// long work blocks separated by short breaks and ending with a long break.
+func pomodoroSegments(long int) ([]string, []string) {
+	var durations, names []string
+	for i := 1; i <= max(long, 1); i++ {
+		durations = append(durations, "25m")
+		names = append(names, "Work")
+		if i < long {
+			durations = append(durations, "5m")
+			names = append(names, "Break")
+		}
+	}
+	durations = append(durations, "15m")
+	names = append(names, "Long break")
+	return durations, names
+}
+
+// autoProgressColor returns the solid progress bar color of --color auto,
+// which stands out on the background of the terminal: teal on dark and navy
+// on light ones.
+func autoProgressColor(dark bool) barColor {
+	if dark {
+		return barColor{from: "#14B8A6"}
+	}
+	return barColor{from: "#1E3A8A"}
+}
+
+func progressColor(theme string) (barColor, error) {
+	switch strings.ToLower(theme) {
+	case "", "default":
+		return barColor{"#5A56E0", "#EE6FF8"}, nil
+	case "green":
+		return barColor{"#1A7F37", "#7EE787"}, nil
+	case "blue":
+		return barColor{"#0550AE", "#79C0FF"}, nil
+	case "red":
+		return barColor{"#A40E26", "#FF7B72"}, nil
+	case "rainbow":
+		return barColor{"#FF0000", "#0000FF"}, nil
+	case "none":
+		return barColor{from: "#7571F9"}, nil
+	default:
+		return barColor{}, fmt.Errorf("unknown color %q", theme)
+	}
+}
+
+// startHook launches command through sh -c without waiting for it, replacing
+// the %n placeholder with the timer name. The returned command may be nil if
+// it could not be started, in which case a warning has already been printed.
+func startHook(command string, name string) *exec.Cmd {
+	c := exec.Command("sh", "-c", strings.ReplaceAll(command, "%n", name))
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not run exec hook: %v\n", err)
+		return nil
+	}
+	return c
+}
+
+// clockLayout returns the time layout for a --format value.
+func clockLayout(format string) string {
+	switch strings.ToLower(format) {
+	case "24h":
+		return "15:04" // See: https://golang.cafe/blog/golang-time-format-example.html
+	default:
+		return time.Kitchen
+	}
+}
+
+// untilClock returns the duration until the next occurrence of the wall-clock
+// time s, parsed with the configured format and falling back to the other.
+func untilClock(s string, format string) (time.Duration, error) {
+	layouts := []string{clockLayout(format), "15:04", time.Kitchen}
+	var clock time.Time
+	var err error
+	for _, layout := range layouts {
+		clock, err = time.Parse(layout, strings.ToUpper(strings.ReplaceAll(s, " ", "")))
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected e.g. 17:00 or 5:00PM", s)
+	}
+
+	now := time.Now()
+	target := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !target.After(now) {
+		target = target.AddDate(0, 0, 1)
+	}
+	return time.Until(target), nil
+}
+
+// noColor reports whether colors and text styles are turned off by setting
+// NO_COLOR to any value, see https://no-color.org.
+func noColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// defaultDuration is used when no duration argument is given.
+func defaultDuration() string {
+	if d := os.Getenv("TOKI_DEFAULT_DURATION"); d != "" {
+		return d
+	}
+	return "25m"
+}
+
+// padRight pads s with spaces to the given display width, counting wide
+// characters such as emoji and CJK as two columns.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
+
+// writeTable writes rows as columns two spaces apart. Unlike text/tabwriter
+// it aligns by display width, so names with wide characters line up.
+func writeTable(w io.Writer, rows [][]string) error {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if i < len(row)-1 {
+				cell = padRight(cell, widths[i])
+			}
+			cells[i] = cell
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "  ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatClock formats d as HH:MM:SS.
+func formatClock(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// decimalRe matches plain decimal numbers such as 90, 1.5 or .5. Other forms
+// accepted by strconv.ParseFloat (1e3, Inf, 0x10) are not valid durations even
+// with a suffix.
+var decimalRe = regexp.MustCompile(`^(\d+\.?\d*|\.\d+)$`)
+
 func addSuffixIfArgIsNumber(s string, suffix string) string {
-	_, err := strconv.ParseFloat(s, 64)
-	if err == nil {
+	if decimalRe.MatchString(s) {
 		s = s + suffix
 		return s
 	}
 	return s
 }
 
-func splitTimerArgString(s string) []string {
-	const TIMER_ARG_SEP = "\\s*[\\s,-]\\s*"
-	array := regexp.MustCompile(TIMER_ARG_SEP).Split(s, -1)
+func parseDurations(timerStringArray []string) ([]time.Duration, error) {
+	var durations []time.Duration
+	for index, item := range timerStringArray {
+		raw := item
+		if item == "" {
+			return nil, fmt.Errorf("segment %d is empty, check for doubled or trailing separators", index+1)
+		}
+		if d, ok := parseColonDuration(item); ok {
+			durations = append(durations, d)
+			continue
+		}
+		iso, ok, err := isoToGoDuration(item)
+		if err != nil {
+			return nil, fmt.Errorf("segment %d %q: %w", index+1, raw, err)
+		}
+		if ok {
+			item = iso
+		}
+		timerStringArray[index] = addSuffixIfArgIsNumber(item, "s")
+
+		duration, err := time.ParseDuration(timerStringArray[index])
+		if err != nil {
+			return nil, fmt.Errorf("segment %d %q: %w – accepted formats: 30s, 5m, 1h30m or 1:30:00", index+1, raw, err)
+		}
+		durations = append(durations, duration)
+	}
+	return durations, nil
+}
+
+// parseColonDuration parses s, m:s or h:m:s durations such as 1:30:00. Fields
+// may exceed their usual range, so 0:90 is 90 seconds.
+func parseColonDuration(s string) (time.Duration, bool) {
+	fields := strings.Split(s, ":")
+	if len(fields) > 3 {
+		return 0, false
+	}
+
+	var d time.Duration
+	for i, field := range fields {
+		if !decimalRe.MatchString(field) {
+			return 0, false
+		}
+		if i < len(fields)-1 && strings.Contains(field, ".") {
+			return 0, false // only the seconds may be fractional
+		}
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return 0, false
+		}
+		unit := []time.Duration{time.Second, time.Minute, time.Hour}[len(fields)-1-i]
+		d += time.Duration(v * float64(unit))
+	}
+	return d, true
+}
+
+var isoDurationRe = regexp.MustCompile(`(?i)^P(?:([\d.]+)D)?(T)?(?:([\d.]+)H)?(?:([\d.]+)M)?(?:([\d.]+)S)?$`)
+
+// isoToGoDuration converts an ISO 8601 duration such as PT25M or P1H30M to a
+// Go duration string, reporting whether s was one. Days are converted to 24
+// hours, years, months and weeks are not supported. M is minutes after T or
+// hours, anywhere else it is months and an error.
+func isoToGoDuration(s string) (string, bool, error) {
+	match := isoDurationRe.FindStringSubmatch(s)
+	if match == nil || strings.EqualFold(s, "P") || strings.EqualFold(s, "PT") {
+		return "", false, nil
+	}
+	if match[4] != "" && match[2] == "" && match[3] == "" {
+		return "", true, fmt.Errorf("months are not supported, use PT%sM for minutes", match[4])
+	}
+
+	var result string
+	if match[1] != "" {
+		days, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return "", false, nil
+		}
+		result += strconv.FormatFloat(days*24, 'f', -1, 64) + "h"
+	}
+	for i, unit := range []string{"h", "m", "s"} {
+		if match[i+3] != "" {
+			result += match[i+3] + unit
+		}
+	}
+	return result, true, nil
+}
+
+// splitDelays strips the + prefix marking delay segments, such as +2m in
+// "+2m 25m", and reports which segments had it.
+func splitDelays(timerStringArray []string) ([]string, []bool) {
+	delays := make([]bool, len(timerStringArray))
+	for i, item := range timerStringArray {
+		if strings.HasPrefix(item, "+") {
+			timerStringArray[i] = strings.TrimPrefix(item, "+")
+			delays[i] = true
+		}
+	}
+	return timerStringArray, delays
+}
+
+// readTimerArgs reads one duration per line, skipping blank lines and lines
+// starting with #.
+func readTimerArgs(r io.Reader) ([]string, error) {
+	var array []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		array = append(array, line)
+	}
+	return array, scanner.Err()
+}
+
+func splitNames(s string) []string {
+	if s == "" {
+		return nil
+	}
+	names := strings.Split(s, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return names
+}
+
+// TIMER_ARG_SEP also matches Unicode spaces, which \s alone does not.
+const TIMER_ARG_SEP = "[\\s\\p{Zs}]*[\\s\\p{Zs},-][\\s\\p{Zs}]*"
+
+func splitTimerArgString(s string, sep *regexp.Regexp) []string {
+	array := sep.Split(strings.TrimSpace(s), -1)
+	for i := range array {
+		array[i] = strings.TrimSpace(array[i])
+	}
 	return array
 }
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+func TestSplitTimerArgString(t *testing.T) {
+	sep := regexp.MustCompile(TIMER_ARG_SEP)
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"25m", []string{"25m"}},
+		{"25m,5m", []string{"25m", "5m"}},
+		{"25m - 5m", []string{"25m", "5m"}},
+		{"25m  5m", []string{"25m", "5m"}},
+		{"25m\u00a05m", []string{"25m", "5m"}},
+		{"25m\u30005m", []string{"25m", "5m"}},
+		{" 25m ", []string{"25m"}},
+		{"25m,  ,5m", []string{"25m", "", "5m"}},
+	}
+	for _, tt := range tests {
+		if got := splitTimerArgString(tt.in, sep); !slices.Equal(got, tt.want) {
+			t.Errorf("splitTimerArgString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDurationsEmptySegment(t *testing.T) {
+	sep := regexp.MustCompile(TIMER_ARG_SEP)
+	_, err := parseDurations(splitTimerArgString("25m,  ,5m", sep))
+	if err == nil || !strings.Contains(err.Error(), "segment 2 is empty") {
+		t.Errorf("parseDurations(25m,  ,5m) error = %v, want segment 2 is empty", err)
+	}
+}
+
+func TestParseDurationsDecimals(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want []time.Duration
+	}{
+		{[]string{"1.5m", "0.5m"}, []time.Duration{90 * time.Second, 30 * time.Second}},
+		{[]string{"1.5"}, []time.Duration{1500 * time.Millisecond}},
+		{[]string{"90"}, []time.Duration{90 * time.Second}},
+		{[]string{"0.5h"}, []time.Duration{30 * time.Minute}},
+	}
+	for _, tt := range tests {
+		got, err := parseDurations(slices.Clone(tt.in))
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("parseDurations(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseColonDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"0:90", 90 * time.Second, true},
+		{"1:00:00", time.Hour, true},
+		{"1:30", 90 * time.Second, true},
+		{"0:1.5", 1500 * time.Millisecond, true},
+		{"1.5:00", 0, false},
+		{"1:0.5:00", 0, false},
+		{"1:00:00:00", 0, false},
+		{"1::00", 0, false},
+		{"5m", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseColonDuration(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseColonDuration(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestWriteTableWideCharacters(t *testing.T) {
+	var b strings.Builder
+	rows := [][]string{
+		{"NAME", "DURATION"},
+		{"🍅 Work", "25m"},
+		{"作業", "50m"},
+		{"break", "5m"},
+	}
+	if err := writeTable(&b, rows); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	col := lipgloss.Width("🍅 Work") + 2
+	for _, line := range lines {
+		// The second column starts at the same display column on every line.
+		var prefix string
+		for _, r := range line {
+			if lipgloss.Width(prefix) == col {
+				break
+			}
+			prefix += string(r)
+		}
+		if lipgloss.Width(prefix) != col || strings.HasPrefix(line[len(prefix):], " ") {
+			t.Errorf("line %q is not aligned at column %d", line, col)
+		}
+	}
+}
+
+func TestPadRight(t *testing.T) {
+	for _, s := range []string{"abc", "作業", "🍅 Work"} {
+		if got := lipgloss.Width(padRight(s, 10)); got != 10 {
+			t.Errorf("padRight(%q, 10) is %d columns wide, want 10", s, got)
+		}
+	}
+}
+
+func TestWritePlanRepeatedDelays(t *testing.T) {
+	tests := []struct {
+		durations []time.Duration
+		delays    []bool
+		repeat    int
+		want      string
+	}{
+		{[]time.Duration{25 * time.Minute, 5 * time.Minute}, []bool{false, false}, 3, "Total: 30m × 3 = 1h30m\n"},
+		{[]time.Duration{2 * time.Minute, 25 * time.Minute, 5 * time.Minute}, []bool{true, false, false}, 3, "Total: 2m + 30m × 3 = 1h32m\n"},
+		{[]time.Duration{2 * time.Minute, 25 * time.Minute}, []bool{true, false}, 1, "Total: 27m\n"},
+		{[]time.Duration{2 * time.Minute, 25 * time.Minute}, []bool{true, false}, -1, "Total: 2m + 25m, repeated forever\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		m := model{durations: tt.durations, delays: tt.delays, repeat: tt.repeat}
+		if err := m.writePlan(&b); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.SplitAfter(b.String(), "\n")
+		if got := lines[len(lines)-2]; got != tt.want {
+			t.Errorf("writePlan(%v, %v, repeat %d) total = %q, want %q", tt.durations, tt.delays, tt.repeat, got, tt.want)
+		}
+	}
+}
+
+func TestSegmentIndicatorSkipsDelays(t *testing.T) {
+	tests := []struct {
+		delays []bool
+		state  int
+		want   string
+	}{
+		{[]bool{false, false}, 1, "[2/2] "},
+		{[]bool{true, false}, 0, ""},
+		{[]bool{true, false}, 1, ""},
+		{[]bool{true, false, false}, 0, "[1/2] "},
+		{[]bool{true, false, false}, 2, "[2/2] "},
+		{[]bool{false, true, false}, 2, "[2/2] "},
+	}
+	for _, tt := range tests {
+		m := model{durations: make([]time.Duration, len(tt.delays)), delays: tt.delays, state: tt.state}
+		if got := m.segmentIndicator(); got != tt.want {
+			t.Errorf("segmentIndicator(%v, state %d) = %q, want %q", tt.delays, tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestTotalViewSkipsDelays(t *testing.T) {
+	tests := []struct {
+		delays []bool
+		want   bool
+	}{
+		{[]bool{false}, false},
+		{[]bool{true, false}, false},
+		{[]bool{false, false}, true},
+		{[]bool{true, false, false}, true},
+	}
+	for _, tt := range tests {
+		m := model{durations: make([]time.Duration, len(tt.delays)), delays: tt.delays}
+		if got := m.totalView() != ""; got != tt.want {
+			t.Errorf("totalView(%v) shown = %v, want %v", tt.delays, got, tt.want)
+		}
+		if got := m.showTotalBar(); got != tt.want {
+			t.Errorf("showTotalBar(%v) = %v, want %v", tt.delays, got, tt.want)
+		}
+	}
+}
+
+func TestISOToGoDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"PT25M", "25m", false},
+		{"PT1M", "1m", false},
+		{"P1H30M", "1h30m", false},
+		{"P1DT30M", "24h30m", false},
+		{"P1M", "", true},
+		{"P1D30M", "", true},
+	}
+	for _, tt := range tests {
+		got, ok, err := isoToGoDuration(tt.in)
+		if (err != nil) != tt.wantErr || !ok || got != tt.want {
+			t.Errorf("isoToGoDuration(%q) = %q, %v, %v, want %q, error %v", tt.in, got, ok, err, tt.want, tt.wantErr)
+		}
+	}
+}
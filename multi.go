@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/timer"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/spf13/cobra"
+)
+
+// multiModel runs the timers of toki multi at the same time, one below the
+// other. Keys other than quit and interrupt act on every timer.
+type multiModel struct {
+	timers       []model
+	quitting     bool
+	interrupting bool
+}
+
+func (m multiModel) Init() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, t := range m.timers {
+		cmds = append(cmds, t.startTimer())
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m multiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case signalMsg:
+		m.interrupting = true
+		return m, tea.Quit
+	case tea.KeyMsg:
+		if key.Matches(msg, quitKeys) {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		if key.Matches(msg, intKeys) {
+			m.interrupting = true
+			return m, tea.Quit
+		}
+	}
+
+	// Timer and progress messages carry the ID of their timer, the others
+	// ignore them.
+	var cmds []tea.Cmd
+	for i, t := range m.timers {
+		if t.completed {
+			continue
+		}
+		next, cmd := t.Update(msg)
+		m.timers[i] = next.(model)
+		cmds = append(cmds, cmd)
+	}
+	for _, t := range m.timers {
+		if !t.completed {
+			return m, tea.Batch(cmds...)
+		}
+	}
+	return m, sequence(tea.Batch(cmds...), tea.Quit)
+}
+
+func (m multiModel) View() string {
+	if m.quitting || m.interrupting {
+		return ""
+	}
+	views := make([]string, len(m.timers))
+	for i, t := range m.timers {
+		if !t.completed {
+			views[i] = t.View()
+			continue
+		}
+		name := t.segmentName()
+		if name == "" {
+			name = fmt.Sprintf(tr("timer"), i+1)
+		}
+		views[i] = italicStyle.Render(name) + " " + tr("finished")
+	}
+	result := strings.Join(views, "\n\n")
+	return altscreenStyle.
+		MarginTop(altscreenMargin(result)).
+		Render(result)
+}
+
+// newMultiTimer returns the timer of a toki multi argument, made of the
+// durations followed by the name, e.g. "25m work".
+func newMultiTimer(arg string, sep *regexp.Regexp, theme Theme, urgency urgencyConfig) (model, error) {
+	durationArg, timerName, _ := strings.Cut(strings.TrimSpace(arg), " ")
+	timerStrings, delays := splitDelays(splitTimerArgString(expandPreset(durationArg), sep))
+	durations, err := parseDurations(timerStrings)
+	if err != nil {
+		return model{}, err
+	}
+	if len(durations) == 0 {
+		return model{}, fmt.Errorf("no durations given in %q", arg)
+	}
+
+	var bar urgencyBar
+	if !noProgress {
+		bar = newUrgencyBar(theme, progressStyles[progressStyle], reverse, !noUrgencyColors, urgency)
+	}
+	now := time.Now()
+	return model{
+		durations:       durations,
+		delays:          delays,
+		timer:           timer.New(durations[0], timer.WithInterval(timerInterval(durations[0]))),
+		progress:        bar,
+		totalBar:        newTotalBar(bar.Width()),
+		noProgress:      noProgress,
+		noTotal:         noTotal,
+		noTotalBar:      noTotalBar,
+		names:           splitNames(strings.TrimSpace(timerName)),
+		repeat:          1,
+		speed:           1,
+		embedded:        true,
+		bell:            bell,
+		startTimeFormat: startTimeFormat,
+		location:        time.Local,
+		labelPosition:   "top",
+		timerFormat:     strings.ToLower(timerFormat),
+		start:           now,
+		sessionStart:    now,
+	}, nil
+}
+
+var multiCmd = &cobra.Command{
+	Use:          "multi <timer>...",
+	Short:        "Runs several timers at once",
+	Example:      `  toki multi "25m work" "50m gym"`,
+	SilenceUsage: true,
+	Args:         cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sep, err := regexp.Compile(separator)
+		if err != nil {
+			return fmt.Errorf("invalid separator: %w", err)
+		}
+		theme, err := loadTheme(themeName)
+		if err != nil {
+			return err
+		}
+		boldStyle, italicStyle = theme.Bold, theme.Italic
+		opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithoutSignalHandler()}
+		if noColor() {
+			boldStyle, italicStyle = lipgloss.NewStyle(), lipgloss.NewStyle()
+			opts = append(opts, tea.WithColorProfile(colorprofile.Ascii))
+		}
+		urgency := urgencyConfig{Warning: 75, Critical: 90}
+		if err := configSection("urgency", &urgency); err != nil {
+			return err
+		}
+		if err := loadKeybindings(keybinds); err != nil {
+			return err
+		}
+
+		var initial multiModel
+		for _, arg := range args {
+			t, err := newMultiTimer(arg, sep, theme, urgency)
+			if err != nil {
+				return err
+			}
+			initial.timers = append(initial.timers, t)
+		}
+		altscreenStyle = altscreenStyle.MarginLeft(padding)
+
+		p := tea.NewProgram(initial, opts...)
+		stopSignals := forwardSignals(p)
+		m, err := p.Run()
+		stopSignals()
+		if err != nil {
+			return err
+		}
+		switch {
+		case m.(multiModel).interrupting:
+			return &ExitError{Code: 1, Err: errors.New("interrupted")}
+		case m.(multiModel).quitting:
+			return &ExitError{Code: 2}
+		}
+		cmd.Println(tr("finished"))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(multiCmd)
+}
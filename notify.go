@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+// notify shows a desktop notification using the notifier available on the
+// current OS.
+func notify(title, body string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		c = exec.Command("notify-send", title, body)
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s",
+			strconv.Quote(body), strconv.Quote(title))
+		c = exec.Command("osascript", "-e", script)
+	case "windows":
+		c = exec.Command("toast", "--title", title, "--message", body)
+	default:
+		return fmt.Errorf("notifications are not supported on %s", runtime.GOOS)
+	}
+	return c.Run()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/spf13/cobra"
+)
+
+// pipeMsg is a command read from the --pipe FIFO.
+type pipeMsg controlMsg
+
+// readPipe returns a command waiting for the next line of the --pipe FIFO,
+// or nil without one. It is issued again after every command read.
+func (m model) readPipe() tea.Cmd {
+	if m.pipe == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		line, err := m.pipe.ReadString('\n')
+		if err != nil {
+			return nil
+		}
+		return pipeMsg{strings.TrimSpace(line)}
+	}
+}
+
+// openPipe creates the FIFO of --pipe and opens it for reading. The returned
+// function closes and removes it.
+func openPipe(path string) (*bufio.Reader, func(), error) {
+	f, err := makePipe(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("--pipe: %w", err)
+	}
+	return bufio.NewReader(f), func() {
+		f.Close()
+		_ = removePipe(path)
+	}, nil
+}
+
+var stopPipe string
+
+var stopCmd = &cobra.Command{
+	Use:          "stop --pipe <path>",
+	Short:        "Stops the timer started with the same --pipe",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return writePipe(stopPipe, "stop\n")
+	},
+}
+
+func init() {
+	stopCmd.Flags().StringVarP(&stopPipe, "pipe", "", "", "named pipe the timer reads commands from")
+	_ = stopCmd.MarkFlagRequired("pipe")
+	rootCmd.AddCommand(stopCmd)
+}
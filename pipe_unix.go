@@ -0,0 +1,54 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// makePipe creates a FIFO at path, or reuses the one left there, and opens
+// it. It is opened for writing too so that reads block instead of returning
+// EOF whenever a writer closes it.
+func makePipe(path string) (*os.File, error) {
+	err := syscall.Mkfifo(path, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		if info, statErr := os.Stat(path); statErr != nil || info.Mode()&os.ModeNamedPipe == 0 {
+			return nil, fmt.Errorf("%s exists and is not a named pipe", path)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_RDWR, 0)
+}
+
+// removePipe removes the FIFO created by makePipe.
+func removePipe(path string) error {
+	return os.Remove(path)
+}
+
+// writePipe writes s to the FIFO at path, failing instead of blocking when no
+// timer reads it.
+func writePipe(path, s string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return fmt.Errorf("%s is not a named pipe", path)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if errors.Is(err, syscall.ENXIO) {
+		return fmt.Errorf("no timer is reading %s", path)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(s); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+var errNoPipes = errors.New("named pipes are not supported on windows")
+
+// makePipe is not supported on Windows, which has no FIFOs.
+func makePipe(string) (*os.File, error) {
+	return nil, errNoPipes
+}
+
+func removePipe(string) error {
+	return errNoPipes
+}
+
+func writePipe(string, string) error {
+	return errNoPipes
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+// preset is a named duration argument defined in TOKI_PRESETS.
+type preset struct {
+	name      string
+	durations string
+}
+
+// presetsPath is the file toki import writes presets to.
+func presetsPath() string {
+	return filepath.Join(filepath.Dir(defaultConfigPath()), "presets.toml")
+}
+
+// readPresets parses TOKI_PRESETS, e.g. "pomo=25m,5m;standup=15m", in the
+// order the presets are defined, followed by the [presets] section of the
+// config file and then the presets file, both sorted by name. Malformed
+// entries are skipped and for presets of the same name the first wins.
+func readPresets() []preset {
+	var presets []preset
+	defined := map[string]bool{}
+	for _, entry := range strings.Split(os.Getenv("TOKI_PRESETS"), ";") {
+		name, durations, ok := strings.Cut(entry, "=")
+		name, durations = strings.TrimSpace(name), strings.TrimSpace(durations)
+		if !ok || name == "" || durations == "" || defined[name] {
+			continue
+		}
+		presets = append(presets, preset{name, durations})
+		defined[name] = true
+	}
+
+	// Errors in the config file are already reported when it is loaded.
+	var section, imported map[string]string
+	_ = configSection("presets", &section)
+	_, _ = toml.DecodeFile(presetsPath(), &imported)
+	for _, file := range []map[string]string{section, imported} {
+		for _, name := range slices.Sorted(maps.Keys(file)) {
+			if durations := strings.TrimSpace(file[name]); !defined[name] && durations != "" {
+				presets = append(presets, preset{name, durations})
+				defined[name] = true
+			}
+		}
+	}
+	return presets
+}
+
+// expandPreset returns the durations of the preset called arg, or arg itself
+// if there is no such preset.
+func expandPreset(arg string) string {
+	for _, p := range readPresets() {
+		if p.name == arg {
+			return p.durations
+		}
+	}
+	return arg
+}
+
+var presetsCmd = &cobra.Command{
+	Use:          "presets",
+	Short:        "Lists the presets defined in TOKI_PRESETS, the config file and the presets file",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		presets := readPresets()
+		if len(presets) == 0 {
+			cmd.Println(`no presets, define them like TOKI_PRESETS="pomo=25m,5m;standup=15m" or in the [presets] section of the config file`)
+			return nil
+		}
+
+		var rows [][]string
+		for _, p := range presets {
+			rows = append(rows, []string{p.name, p.durations})
+		}
+		return writeTable(cmd.OutOrStdout(), rows)
+	},
+}
+
+// completePresets suggests the preset names for the duration argument.
+func completePresets(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, p := range readPresets() {
+		if strings.HasPrefix(p.name, toComplete) {
+			names = append(names, p.name+"\t"+p.durations)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.ValidArgsFunction = completePresets
+	rootCmd.AddCommand(presetsCmd)
+}
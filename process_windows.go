@@ -0,0 +1,38 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// processAlive reports whether a process with the given pid is running. An
+// exited process can still be opened while handles to it remain, so its exit
+// code is checked too.
+func processAlive(pid int) bool {
+	h, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(h)
+	var code uint32
+	if err := windows.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// stillActive is the exit code of a process that is still running.
+const stillActive = 259
+
+// terminateProcess asks the process with the given pid to exit. Windows has no
+// SIGTERM so the process is killed.
+func terminateProcess(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
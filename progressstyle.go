@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+// Renderer draws a progress indicator for a percentage between 0 and 1 in
+// at most width cells.
+type Renderer interface {
+	Render(pct float64, width int) string
+}
+
+// progressStyles are the renderers of --progress-style. The full style has
+// none, it is the animated gradient of progress.Model.
+var progressStyles = map[string]Renderer{
+	"full":   nil,
+	"bar":    asciiRenderer{},
+	"dots":   dotsRenderer{},
+	"circle": circleRenderer{},
+}
+
+// asciiRenderer draws the progress as [=====>    ].
+type asciiRenderer struct{}
+
+func (asciiRenderer) Render(pct float64, width int) string {
+	inner := max(width-2, 1)
+	filled := int(math.Round(pct * float64(inner)))
+	bar := strings.Repeat("=", filled)
+	if filled > 0 && filled < inner {
+		bar = bar[1:] + ">"
+	}
+	return "[" + bar + strings.Repeat(" ", inner-filled) + "]"
+}
+
+// dotsRenderer draws the progress as a row of braille cells filling dot by
+// dot.
+type dotsRenderer struct{}
+
+// brailleSteps are the braille cells with 0 to 8 of their dots set.
+var brailleSteps = []rune("⠀⡀⡄⡆⡇⣇⣧⣷⣿")
+
+func (dotsRenderer) Render(pct float64, width int) string {
+	width = max(width, 1)
+	dots := int(math.Round(pct * float64(width*8)))
+	var b strings.Builder
+	for range width {
+		n := min(dots, 8)
+		b.WriteRune(brailleSteps[n])
+		dots -= n
+	}
+	return b.String()
+}
+
+// circleRenderer draws the progress as a single circle filling like a pie.
+type circleRenderer struct{}
+
+var circleSteps = []rune("○◔◑◕●")
+
+func (circleRenderer) Render(pct float64, _ int) string {
+	return string(circleSteps[int(math.Round(pct*float64(len(circleSteps)-1)))])
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/spf13/cobra"
+)
+
+// controlCommands are the lines of the control protocol, each acting like the
+// key of the same name. Stop is the same as quit.
+var controlCommands = map[string]bool{
+	"pause": true, "resume": true, "skip": true, "restart": true, "quit": true, "stop": true,
+}
+
+// controlMsg is a control command received on the socket or the --pipe of the
+// timer.
+type controlMsg struct {
+	command string
+}
+
+func controlPath(pid int) string {
+	return filepath.Join(instanceDir(), strconv.Itoa(pid)+".sock")
+}
+
+// serveControl listens on the control socket of this process and sends the
+// commands it receives to p. Each command is answered with ok or an error.
+// The returned function closes the socket.
+func serveControl(p *tea.Program) (func(), error) {
+	path := controlPath(os.Getpid())
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	_ = os.Remove(path)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveLines(conn, func(command string) error {
+				p.Send(controlMsg{command})
+				return nil
+			})
+		}
+	}()
+	return func() {
+		l.Close()
+		os.Remove(path)
+	}, nil
+}
+
+// serveLines reads the commands of the control protocol from conn until it is
+// closed, passing the known ones to handle.
+func serveLines(conn net.Conn, handle func(string) error) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		command := strings.TrimSpace(scanner.Text())
+		var err error
+		if controlCommands[command] {
+			err = handle(command)
+		} else {
+			err = fmt.Errorf("unknown command %q, expected pause, resume, skip, restart or quit", command)
+		}
+		reply := "ok"
+		if err != nil {
+			reply = "error: " + err.Error()
+		}
+		if _, err := fmt.Fprintln(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// sendControl sends command to the toki with the given pid, or to the only
+// running one when pid is 0, and returns its error reply.
+func sendControl(pid int, command string) error {
+	if pid == 0 {
+		instances, err := readInstances()
+		if err != nil {
+			return err
+		}
+		switch len(instances) {
+		case 0:
+			return errors.New("no running timers")
+		case 1:
+			pid = instances[0].PID
+		default:
+			return errors.New("several running timers, choose one with --pid")
+		}
+	}
+	conn, err := net.Dial("unix", controlPath(pid))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := fmt.Fprintln(conn, command); err != nil {
+		return err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if msg, ok := strings.CutPrefix(strings.TrimSpace(reply), "error: "); ok {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+var (
+	proxyAddr   string
+	proxyPort   int
+	proxySocket string
+	proxyPID    int
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Relays pause, skip and quit commands to a running timer",
+	Long: `Listens on a TCP port or a Unix socket and relays the commands received to a
+running toki. The protocol is one command per line, pause, resume, skip, restart
+or quit, each answered with ok or an error.`,
+	Example:      `  toki proxy --port 9090 & echo pause | nc localhost 9090`,
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		network, addr := "tcp", net.JoinHostPort(proxyAddr, strconv.Itoa(proxyPort))
+		if proxySocket != "" {
+			network, addr = "unix", proxySocket
+			_ = os.Remove(addr)
+			defer os.Remove(addr)
+		}
+		l, err := net.Listen(network, addr)
+		if err != nil {
+			return err
+		}
+		defer l.Close()
+		cmd.Printf("listening on %s\n", addr)
+
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return err
+			}
+			go serveLines(conn, func(command string) error {
+				return sendControl(proxyPID, command)
+			})
+		}
+	},
+}
+
+func init() {
+	proxyCmd.Flags().StringVarP(&proxyAddr, "addr", "", "127.0.0.1", "address to listen on, anyone who can reach it controls the timers")
+	proxyCmd.Flags().IntVarP(&proxyPort, "port", "", 9090, "port to listen on")
+	proxyCmd.Flags().StringVarP(&proxySocket, "socket", "", "", "Unix socket to listen on instead of the port")
+	proxyCmd.Flags().IntVarP(&proxyPID, "pid", "", 0, "pid of the timer to control, needed when several are running")
+	rootCmd.AddCommand(proxyCmd)
+}
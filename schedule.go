@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+)
+
+// cronField is one time field of a parsed cron expression.
+type cronField struct {
+	bits     uint64
+	min, max int
+}
+
+// values returns the values of f, or nil when f matches every value.
+func (f cronField) values() []int {
+	var values []int
+	for v := f.min; v <= f.max; v++ {
+		if f.bits&(1<<v) != 0 {
+			values = append(values, v)
+		}
+	}
+	if len(values) == f.max-f.min+1 {
+		return nil
+	}
+	return values
+}
+
+// cronSpec is a cron expression broken into the fields supported by systemd
+// timers and launchd.
+type cronSpec struct {
+	minute, hour, dom, month, dow cronField
+}
+
+// parseCron parses a standard five field cron expression, e.g. "0 9 * * 1-5".
+func parseCron(expr string) (cronSpec, error) {
+	schedule, err := cron.ParseStandard(expr)
+	if err != nil {
+		return cronSpec{}, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+	}
+	spec, ok := schedule.(*cron.SpecSchedule)
+	if !ok {
+		return cronSpec{}, fmt.Errorf("invalid cron expression %q: @every is not supported", expr)
+	}
+	s := cronSpec{
+		minute: cronField{spec.Minute, 0, 59},
+		hour:   cronField{spec.Hour, 0, 23},
+		dom:    cronField{spec.Dom, 1, 31},
+		month:  cronField{spec.Month, 1, 12},
+		dow:    cronField{spec.Dow, 0, 6},
+	}
+	// cron runs when either day matches, systemd and launchd only when both do.
+	if s.dom.values() != nil && s.dow.values() != nil {
+		return cronSpec{}, fmt.Errorf("invalid cron expression %q: restricting both the day of month and of week is not supported", expr)
+	}
+	return s, nil
+}
+
+var weekdays = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// onCalendar returns s as a systemd OnCalendar value, e.g.
+// "Mon,Tue,Wed,Thu,Fri *-*-* 09:00:00".
+func (s cronSpec) onCalendar() string {
+	list := func(f cronField, format func(int) string) string {
+		values := f.values()
+		if values == nil {
+			return "*"
+		}
+		s := make([]string, len(values))
+		for i, v := range values {
+			s[i] = format(v)
+		}
+		return strings.Join(s, ",")
+	}
+	number := func(v int) string { return fmt.Sprintf("%02d", v) }
+
+	calendar := fmt.Sprintf("*-%s-%s %s:%s:00",
+		list(s.month, number), list(s.dom, number), list(s.hour, number), list(s.minute, number))
+	if s.dow.values() != nil {
+		calendar = list(s.dow, func(v int) string { return weekdays[v] }) + " " + calendar
+	}
+	return calendar
+}
+
+// calendarIntervals returns s as launchd StartCalendarInterval entries, one
+// per combination of the restricted fields.
+func (s cronSpec) calendarIntervals() []map[string]int {
+	intervals := []map[string]int{{}}
+	for _, field := range []struct {
+		key string
+		f   cronField
+	}{{"Minute", s.minute}, {"Hour", s.hour}, {"Day", s.dom}, {"Month", s.month}, {"Weekday", s.dow}} {
+		values := field.f.values()
+		if values == nil {
+			continue
+		}
+		var next []map[string]int
+		for _, interval := range intervals {
+			for _, v := range values {
+				entry := map[string]int{field.key: v}
+				for k, old := range interval {
+					entry[k] = old
+				}
+				next = append(next, entry)
+			}
+		}
+		intervals = next
+	}
+	return intervals
+}
+
+// scheduleMarker prefixes the comment holding the cron expression in the
+// generated files, read back by toki schedule --list.
+const scheduleMarker = "toki schedule: "
+
+// scheduleID returns the name of the schedule running timer.
+func scheduleID(timer string) string {
+	return strings.Trim(presetNameRe.ReplaceAllString(strings.ToLower(timer), "-"), "-")
+}
+
+// scheduleFiles returns the files of the schedule id, the unit that is
+// enabled coming first.
+func scheduleFiles(id string) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	switch runtime.GOOS {
+	case "linux":
+		dir := filepath.Join(cmp.Or(os.Getenv("XDG_CONFIG_HOME"), filepath.Join(home, ".config")), "systemd", "user")
+		return []string{filepath.Join(dir, "toki-"+id+".timer"), filepath.Join(dir, "toki-"+id+".service")}, nil
+	case "darwin":
+		return []string{filepath.Join(home, "Library", "LaunchAgents", "com.toki."+id+".plist")}, nil
+	default:
+		return nil, fmt.Errorf("schedules are not supported on %s", runtime.GOOS)
+	}
+}
+
+// runService runs a systemctl or launchctl command, including its output in
+// the error.
+func runService(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// scheduleEnabled reports whether the schedule id is running.
+func scheduleEnabled(id string) bool {
+	if runtime.GOOS == "linux" {
+		return exec.Command("systemctl", "--user", "is-enabled", "--quiet", "toki-"+id+".timer").Run() == nil
+	}
+	return exec.Command("launchctl", "list", "com.toki."+id).Run() == nil
+}
+
+// setScheduleEnabled starts or stops running the schedule id.
+func setScheduleEnabled(id string, enabled bool) error {
+	files, err := scheduleFiles(id)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(files[0]); err != nil {
+		return fmt.Errorf("no schedule %q", id)
+	}
+	switch runtime.GOOS {
+	case "linux":
+		verb := "disable"
+		if enabled {
+			verb = "enable"
+		}
+		return runService("systemctl", "--user", verb, "--now", filepath.Base(files[0]))
+	default:
+		verb := "unload"
+		if enabled {
+			verb = "load"
+		}
+		return runService("launchctl", verb, "-w", files[0])
+	}
+}
+
+// installSchedule writes the files running toki with args on spec and
+// enables them.
+func installSchedule(id, expr string, spec cronSpec, args []string) error {
+	files, err := scheduleFiles(id)
+	if err != nil {
+		return err
+	}
+	bin, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args = append([]string{bin}, args...)
+
+	contents := make([]string, len(files))
+	switch runtime.GOOS {
+	case "linux":
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = strconv.Quote(arg)
+		}
+		contents[0] = fmt.Sprintf("# %s%s\n[Unit]\nDescription=toki %s\n\n[Timer]\nOnCalendar=%s\n\n[Install]\nWantedBy=timers.target\n",
+			scheduleMarker, expr, id, spec.onCalendar())
+		contents[1] = fmt.Sprintf("[Unit]\nDescription=toki %s\n\n[Service]\nType=oneshot\nExecStart=%s\n",
+			id, strings.Join(quoted, " "))
+	default:
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+		b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+		fmt.Fprintf(&b, "<!-- %s%s -->\n<plist version=\"1.0\">\n<dict>\n", scheduleMarker, expr)
+		fmt.Fprintf(&b, "  <key>Label</key>\n  <string>com.toki.%s</string>\n", xmlEscape(id))
+		b.WriteString("  <key>ProgramArguments</key>\n  <array>\n")
+		for _, arg := range args {
+			fmt.Fprintf(&b, "    <string>%s</string>\n", xmlEscape(arg))
+		}
+		b.WriteString("  </array>\n  <key>StartCalendarInterval</key>\n  <array>\n")
+		for _, interval := range spec.calendarIntervals() {
+			b.WriteString("    <dict>\n")
+			for _, key := range []string{"Minute", "Hour", "Day", "Month", "Weekday"} {
+				if v, ok := interval[key]; ok {
+					fmt.Fprintf(&b, "      <key>%s</key>\n      <integer>%d</integer>\n", key, v)
+				}
+			}
+			b.WriteString("    </dict>\n")
+		}
+		b.WriteString("  </array>\n</dict>\n</plist>\n")
+		contents[0] = b.String()
+	}
+
+	for i, path := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(contents[i]), 0o644); err != nil {
+			return err
+		}
+	}
+	if runtime.GOOS == "linux" {
+		if err := runService("systemctl", "--user", "daemon-reload"); err != nil {
+			return err
+		}
+	}
+	return setScheduleEnabled(id, true)
+}
+
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// removeSchedule disables the schedule id and deletes its files.
+func removeSchedule(id string) error {
+	if err := setScheduleEnabled(id, false); err != nil {
+		return err
+	}
+	files, err := scheduleFiles(id)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if runtime.GOOS == "linux" {
+		return runService("systemctl", "--user", "daemon-reload")
+	}
+	return nil
+}
+
+// listSchedules returns the ids of the installed schedules with their cron
+// expressions.
+func listSchedules() ([][2]string, error) {
+	files, err := scheduleFiles("*")
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(files[0])
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(matches)
+	prefix, suffix, _ := strings.Cut(filepath.Base(files[0]), "*")
+
+	var schedules [][2]string
+	for _, path := range matches {
+		id := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), prefix), suffix)
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		var expr string
+		for sc := bufio.NewScanner(f); sc.Scan(); {
+			if _, after, ok := strings.Cut(sc.Text(), scheduleMarker); ok {
+				expr = strings.TrimSpace(strings.TrimSuffix(after, "-->"))
+				break
+			}
+		}
+		f.Close()
+		schedules = append(schedules, [2]string{id, expr})
+	}
+	return schedules, nil
+}
+
+var (
+	scheduleCron    string
+	scheduleList    bool
+	scheduleRemove  bool
+	scheduleEnable  bool
+	scheduleDisable bool
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule --cron <expression> <timer>",
+	Short: "Runs a timer on a recurring cron schedule",
+	Long: "Runs a timer on a recurring cron schedule with a systemd user timer on Linux or a launchd agent on macOS.\n" +
+		"Scheduled timers run without a terminal, so they are silent and show a desktop notification when they finish.",
+	Example: `  toki schedule --cron "0 9 * * 1-5" pomo
+  toki schedule --list
+  toki schedule --disable pomo`,
+	SilenceUsage: true,
+	Args:         cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if scheduleList {
+			schedules, err := listSchedules()
+			if err != nil {
+				return err
+			}
+			if len(schedules) == 0 {
+				cmd.Println("no schedules")
+				return nil
+			}
+			rows := [][]string{{"NAME", "CRON", "STATUS"}}
+			for _, s := range schedules {
+				status := "disabled"
+				if scheduleEnabled(s[0]) {
+					status = "enabled"
+				}
+				rows = append(rows, []string{s[0], s[1], status})
+			}
+			return writeTable(cmd.OutOrStdout(), rows)
+		}
+
+		if len(args) == 0 {
+			return fmt.Errorf("missing the timer to schedule, e.g. pomo or 25m")
+		}
+		id := scheduleID(args[0])
+		if id == "" {
+			return fmt.Errorf("invalid timer %q", args[0])
+		}
+		switch {
+		case scheduleRemove:
+			return removeSchedule(id)
+		case scheduleEnable, scheduleDisable:
+			return setScheduleEnabled(id, scheduleEnable)
+		case scheduleCron == "":
+			return fmt.Errorf("missing --cron, e.g. --cron \"0 9 * * 1-5\"")
+		}
+
+		spec, err := parseCron(scheduleCron)
+		if err != nil {
+			return err
+		}
+		sep, err := regexp.Compile(separator)
+		if err != nil {
+			return fmt.Errorf("invalid separator: %w", err)
+		}
+		// Presets are expanded now as TOKI_PRESETS is not set for the
+		// scheduled runs.
+		durations := expandPreset(args[0])
+		timerStrings, _ := splitDelays(splitTimerArgString(durations, sep))
+		if _, err := parseDurations(timerStrings); err != nil {
+			return err
+		}
+		timerArgs := []string{"--silent", "--notify", durations}
+		if durations != args[0] {
+			timerArgs = append(timerArgs, "--name", args[0])
+		}
+		if err := installSchedule(id, scheduleCron, spec, timerArgs); err != nil {
+			return err
+		}
+		cmd.Printf("scheduled %s at %s\n", id, scheduleCron)
+		return nil
+	},
+}
+
+func init() {
+	scheduleCmd.Flags().StringVarP(&scheduleCron, "cron", "", "", "when to run the timer, as a cron expression")
+	scheduleCmd.Flags().BoolVarP(&scheduleList, "list", "", false, "list the schedules")
+	scheduleCmd.Flags().BoolVarP(&scheduleRemove, "remove", "", false, "remove the schedule of the timer")
+	scheduleCmd.Flags().BoolVarP(&scheduleEnable, "enable", "", false, "resume the schedule of the timer")
+	scheduleCmd.Flags().BoolVarP(&scheduleDisable, "disable", "", false, "pause the schedule of the timer")
+	scheduleCmd.MarkFlagsMutuallyExclusive("cron", "list", "remove", "enable", "disable")
+	rootCmd.AddCommand(scheduleCmd)
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// serverTimer is a timer started through the REST API. It runs in its own
+// goroutine until it finishes or is cancelled.
+type serverTimer struct {
+	id        string
+	name      string
+	durations []time.Duration
+	startedAt time.Time
+	cancel    context.CancelFunc
+}
+
+// serverTimerState is the JSON representation of a running serverTimer.
+type serverTimerState struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Durations   []string  `json:"durations"`
+	Segment     int       `json:"segment"`
+	RemainingMS int64     `json:"remaining_ms"`
+	Remaining   string    `json:"remaining"`
+	EndsAt      time.Time `json:"ends_at"`
+}
+
+func (t *serverTimer) state(now time.Time) serverTimerState {
+	passed := now.Sub(t.startedAt)
+	segment := 0
+	var left time.Duration
+	for i, d := range t.durations {
+		if passed < d {
+			segment, left = i, d-passed
+			break
+		}
+		passed -= d
+	}
+	var total time.Duration
+	for _, d := range t.durations {
+		total += d
+	}
+	return serverTimerState{
+		ID:          t.id,
+		Name:        t.name,
+		Durations:   formatDurations(t.durations),
+		Segment:     segment,
+		RemainingMS: left.Milliseconds(),
+		Remaining:   left.Round(time.Second).String(),
+		EndsAt:      t.startedAt.Add(total),
+	}
+}
+
+// timerServer keeps track of the timers started through the REST API.
+type timerServer struct {
+	mu     sync.Mutex
+	timers map[string]*serverTimer
+	nextID int
+	cmd    *cobra.Command
+}
+
+func newTimerServer(cmd *cobra.Command) *timerServer {
+	return &timerServer{timers: map[string]*serverTimer{}, cmd: cmd}
+}
+
+func (s *timerServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /timers", s.createTimer)
+	mux.HandleFunc("GET /timers", s.listTimers)
+	mux.HandleFunc("GET /timers/{id}", s.getTimer)
+	mux.HandleFunc("DELETE /timers/{id}", s.deleteTimer)
+	return mux
+}
+
+// start runs a timer in a new goroutine, removing it once it finished.
+func (s *timerServer) start(name string, durations []time.Duration) *serverTimer {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.nextID++
+	t := &serverTimer{
+		id:        strconv.Itoa(s.nextID),
+		name:      name,
+		durations: durations,
+		startedAt: time.Now(),
+		cancel:    cancel,
+	}
+	s.timers[t.id] = t
+	s.mu.Unlock()
+
+	go func() {
+		var total time.Duration
+		for _, d := range durations {
+			total += d
+		}
+		select {
+		case <-time.After(total):
+			if name != "" {
+				s.cmd.Printf("%s ", name)
+			}
+			s.cmd.Println(tr("finished"))
+		case <-ctx.Done():
+		}
+		s.mu.Lock()
+		delete(s.timers, t.id)
+		s.mu.Unlock()
+	}()
+	return t
+}
+
+func (s *timerServer) createTimer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Durations []string `json:"durations"`
+		Name      string   `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	durations, err := parseDurations(req.Durations)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(durations) == 0 {
+		http.Error(w, "no durations given", http.StatusBadRequest)
+		return
+	}
+
+	t := s.start(req.Name, durations)
+	writeJSON(w, http.StatusCreated, t.state(time.Now()))
+}
+
+func (s *timerServer) listTimers(w http.ResponseWriter, _ *http.Request) {
+	now := time.Now()
+	s.mu.Lock()
+	states := []serverTimerState{}
+	for _, t := range s.timers {
+		states = append(states, t.state(now))
+	}
+	s.mu.Unlock()
+	sort.Slice(states, func(i, j int) bool {
+		a, _ := strconv.Atoi(states[i].ID)
+		b, _ := strconv.Atoi(states[j].ID)
+		return a < b
+	})
+	writeJSON(w, http.StatusOK, states)
+}
+
+func (s *timerServer) getTimer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	t, ok := s.timers[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "no such timer", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, t.state(time.Now()))
+}
+
+func (s *timerServer) deleteTimer(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	t, ok := s.timers[r.PathValue("id")]
+	delete(s.timers, r.PathValue("id"))
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "no such timer", http.StatusNotFound)
+		return
+	}
+	t.cancel()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+var (
+	serverAddr string
+	serverPort int
+)
+
+var serverCmd = &cobra.Command{
+	Use:          "server",
+	Short:        "Serves a REST API to start and cancel timers",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		addr := net.JoinHostPort(serverAddr, strconv.Itoa(serverPort))
+		cmd.Printf("listening on %s\n", addr)
+		return http.ListenAndServe(addr, newTimerServer(cmd).handler())
+	},
+}
+
+func init() {
+	serverCmd.Flags().StringVarP(&serverAddr, "addr", "", "127.0.0.1", "address to listen on, anyone who can reach it controls the timers")
+	serverCmd.Flags().IntVarP(&serverPort, "port", "", 8080, "port to listen on")
+	rootCmd.AddCommand(serverCmd)
+}
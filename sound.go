@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// beepWAV is a short 880Hz sine wave, 8kHz 8-bit mono, played on completion
+// when --bell is set without --sound.
+const beepWAV = `
+UklGRtQEAABXQVZFZm10IBAAAAABAAEAQB8AAEAfAAABAAgAZGF0YbAEAACAv+LXpGErHTx5ut/Z
+qmcwHThztN3br200HjRtr9rctHM5HzFnqdfduHk9IC5io9TevX9CIixcndDewYVIJCpXl8zexItN
+JihSkcjdyJFSKSdOi8Pcy5dYLCZJhb/bzZxeLyVFgLrZ0KFjMyVBerXX0qZpNiU+dLDV06tvOiY7
+b6vS1K90Pyc4aaXQ1bN6Qyg1ZKDM1reASCozX5rJ1ruFTSwxW5XF1b6KUS4wVpDB1cGPVjAvUoq9
+1MSVXDMuToW508aZYTYtSoC10cieZjotR3qwz8qjaz0uRHWrzcuncEEuQXCny8yrdUUvPmyiyM2v
+ekkwPGedxc2yf00yOmKYws21hFE0OV6Tv824iVY2OFqOu827jlo4N1aJt8y9kl87NlOEs8q/l2Q+
+NlB/r8nBm2hBNk17q8fCn21ENkp2p8XEo3JHN0dyo8PEpnZLN0VunsDFqntPOUNpmr7FrX9SOkJm
+lbvFsIRWPEBikbjFsohaPj9ejLXEtIxeQD9biLHDtpBjQj5YhK7CuJRnRT5VgKrBuphrSD5Se6a/
+u5tvSz5Qd6K9vJ9zTj9Oc567vaJ3UUBMcJu5vaV8VEFKbJe2vad/WEJJaZO0vaqDW0RIZY+xvayH
+X0ZHYouuvK6LY0hGX4eru7COZkpGXYOourGSakxGWoClubKVbk9GWHyht7OYcVFHVnietbSbdVRH
+VHWas7WdeVdIU3KXsbWgfFpJUW+Ur7Wif11KUGyQrbWkg2BMT2mNqrSmhmNOT2aJqLSoiWdPTmSG
+pbOpjGpRTmKDorKqj21TTmB/n7CrknBWTl58nK+slHNYT1x5ma2sl3ZbUFt3lqytmXpdUFl0k6qt
+m31gUVhxkKitnYBiU1hvjqasnoJlVFdsi6SsoIVoVVdqiKGroYhrV1ZohZ+qooptWVZngpypo41w
+W1ZlgJqopI9zXVdjfZinpJF1X1diepWmpZN4YVhheJKkpZR7Y1lgdpCipZZ9ZVpfdI2hpJd/aFtf
+coufpJmCalxecImdpJqEbF1eboabo5uGb19ebYSZopuIcWBea4KXoZyKc2JfaoCVoJyMdWRfaX2T
+n52NeGZgaHyRnp2PemdgZ3qOnJ2QfGlhZ3iMm52RfmtiZnaKmZySf21jZnWImJyTgW9kZnSGlpuU
+g3FlZnKFlJuUhXNnZnGDk5qVhnRoZnCBkZmVh3ZpZ3CAj5iViXhrZ29+jpeVinpsaG59jJaVi3tu
+aW57ipSVjH1vaW56iZOVjH5xam15h5KUjX9ya214hpGUjYF0bG53hI+TjoJ1bW52g46SjoN3bm52
+go2RjoR4cG51gYuRjoV5cW91gIqQjoZ6cnB1f4mPjoZ8c3B0foiOjYd9dHF0fYaNjYd+dXJ0fIWM
+jId/dnN1fISLjIh/eHN1e4OKi4iAeXR1e4KJi4iBenV2eoGIioeBe3Z2eoGHiYeCe3d3eoCGiIeC
+fHh3eoCFiIeCfXl4en+Eh4aDfnp5en+DhoaDfnt5e36ChYWDf3t6e36ChISCf3x7fH6Bg4SCgH18
+fH6Bg4OCgH58fX6AgoKCgH59fX6AgYKBgH9+fn+AgYGBgH9/f3+AgICAgH9/f38=
+`
+
+// soundPlayers lists the command line audio players tried, in order, per OS.
+var soundPlayers = map[string][]string{
+	"darwin": {"afplay"},
+	"linux":  {"paplay", "aplay"},
+}
+
+// playSound starts playing the audio file at path without waiting for it to
+// finish.
+func playSound(path string) error {
+	for _, player := range soundPlayers[runtime.GOOS] {
+		bin, err := exec.LookPath(player)
+		if err != nil {
+			continue
+		}
+		c := exec.Command(bin, path)
+		if err := c.Start(); err != nil {
+			return err
+		}
+		return c.Process.Release()
+	}
+	return fmt.Errorf("no audio player found")
+}
+
+// playBeep plays the bundled beep sound. It is written once to the user's
+// cache directory, rather than a shared temporary one, as the player reads it
+// after toki may have exited.
+func playBeep() error {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "toki", "beep.wav")
+	if _, err := os.Stat(path); err != nil {
+		data, err := base64.StdEncoding.DecodeString(beepWAV)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(path, data); err != nil {
+			return err
+		}
+	}
+	return playSound(path)
+}
@@ -0,0 +1,39 @@
+package main
+
+import "strings"
+
+var sparkChars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline is a fixed size ring buffer of percentages rendered as a row of
+// block characters, oldest first.
+type sparkline struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func newSparkline(width int) sparkline {
+	return sparkline{values: make([]float64, max(width, 1))}
+}
+
+// Push records v, overwriting the oldest value once the buffer is full.
+func (s *sparkline) Push(v float64) {
+	s.values[s.next] = v
+	s.next = (s.next + 1) % len(s.values)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+func (s sparkline) View() string {
+	var b strings.Builder
+	start, n := 0, s.next
+	if s.full {
+		start, n = s.next, len(s.values)
+	}
+	for i := 0; i < n; i++ {
+		v := min(max(s.values[(start+i)%len(s.values)], 0), 1)
+		b.WriteRune(sparkChars[int(v*float64(len(sparkChars)-1))])
+	}
+	return b.String()
+}
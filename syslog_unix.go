@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+import "log/syslog"
+
+// writeSyslog logs msg with the toki tag to the system logger at the info
+// level of the user facility.
+func writeSyslog(msg string) error {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "toki")
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return w.Info(msg)
+}
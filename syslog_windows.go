@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "errors"
+
+// writeSyslog is not supported on Windows, which has no syslog.
+func writeSyslog(string) error {
+	return errors.New("syslog is not available on windows")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// Theme holds the styles and colors of the timer display.
+type Theme struct {
+	Bold     lipgloss.Style
+	Italic   lipgloss.Style
+	Progress barColor
+	Warning  barColor
+	Critical barColor
+}
+
+// themeFile is the format of ~/.config/toki/themes/<name>.toml. Colors of the
+// bar take one color for a solid fill or two for a gradient, missing values
+// are taken from the default theme.
+type themeFile struct {
+	Bold     string   `toml:"bold"`
+	Italic   string   `toml:"italic"`
+	Progress []string `toml:"progress"`
+	Warning  []string `toml:"warning"`
+	Critical []string `toml:"critical"`
+}
+
+var themes = map[string]themeFile{
+	"default": {
+		Progress: []string{"#5A56E0", "#EE6FF8"},
+		Warning:  []string{"#D29922", "#F2CC60"},
+		Critical: []string{"#A40E26", "#FF7B72"},
+	},
+	"dracula": {
+		Bold:     "#BD93F9",
+		Italic:   "#F8F8F2",
+		Progress: []string{"#BD93F9", "#FF79C6"},
+		Warning:  []string{"#FFB86C", "#F1FA8C"},
+		Critical: []string{"#FF5555", "#FF79C6"},
+	},
+	"solarized": {
+		Bold:     "#268BD2",
+		Italic:   "#93A1A1",
+		Progress: []string{"#268BD2", "#2AA198"},
+		Warning:  []string{"#CB4B16", "#B58900"},
+		Critical: []string{"#DC322F", "#D33682"},
+	},
+	"nord": {
+		Bold:     "#88C0D0",
+		Italic:   "#D8DEE9",
+		Progress: []string{"#5E81AC", "#88C0D0"},
+		Warning:  []string{"#D08770", "#EBCB8B"},
+		Critical: []string{"#BF616A", "#D08770"},
+	},
+	"gruvbox": {
+		Bold:     "#FABD2F",
+		Italic:   "#EBDBB2",
+		Progress: []string{"#689D6A", "#98971A"},
+		Warning:  []string{"#D65D0E", "#D79921"},
+		Critical: []string{"#CC241D", "#FB4934"},
+	},
+}
+
+// loadTheme returns the built-in theme called name, or reads it from the
+// themes directory next to the config file.
+func loadTheme(name string) (Theme, error) {
+	file, ok := themes[strings.ToLower(name)]
+	if !ok {
+		path := filepath.Join(filepath.Dir(defaultConfigPath()), "themes", name+".toml")
+		if _, err := toml.DecodeFile(path, &file); err != nil {
+			if os.IsNotExist(err) {
+				return Theme{}, fmt.Errorf("unknown theme %q", name)
+			}
+			return Theme{}, err
+		}
+	}
+
+	def := themes["default"]
+	bold := lipgloss.NewStyle().Bold(true)
+	if file.Bold != "" {
+		bold = bold.Foreground(lipgloss.Color(file.Bold))
+	}
+	italic := lipgloss.NewStyle().Italic(true)
+	if file.Italic != "" {
+		italic = italic.Foreground(lipgloss.Color(file.Italic))
+	}
+	return Theme{
+		Bold:     bold,
+		Italic:   italic,
+		Progress: themeColor(file.Progress, def.Progress),
+		Warning:  themeColor(file.Warning, def.Warning),
+		Critical: themeColor(file.Critical, def.Critical),
+	}, nil
+}
+
+func themeColor(colors, fallback []string) barColor {
+	switch len(colors) {
+	case 0:
+		return themeColor(fallback, nil)
+	case 1:
+		return barColor{from: colors[0]}
+	default:
+		return barColor{colors[0], colors[1]}
+	}
+}
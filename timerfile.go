@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// fileSegment is one entry of a timer file read by toki run.
+type fileSegment struct {
+	Duration       string `yaml:"duration"`
+	Name           string `yaml:"name"`
+	ExecOnComplete string `yaml:"exec_on_complete"`
+	Color          string `yaml:"color"`
+
+	duration time.Duration
+	delay    bool
+	color    barColor
+}
+
+// readTimerFile reads the list of segments in the YAML file at path. Every
+// duration is validated, errors are reported with their line number. A
+// duration starting with + is a delay like on the command line.
+func readTimerFile(path string) ([]fileSegment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("%s: no segments", path)
+	}
+	list := doc.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%s:%d: expected a list of segments", path, list.Line)
+	}
+
+	segments := make([]fileSegment, 0, len(list.Content))
+	for _, node := range list.Content {
+		var seg fileSegment
+		if err := node.Decode(&seg); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, node.Line, err)
+		}
+		if seg.Duration == "" {
+			return nil, fmt.Errorf("%s:%d: missing duration", path, node.Line)
+		}
+		duration, delay := strings.CutPrefix(seg.Duration, "+")
+		d, err := parseDurations([]string{duration})
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, node.Line, err)
+		}
+		seg.duration, seg.delay = d[0], delay
+		if seg.Color != "" {
+			if seg.color, err = parseSegmentColor(seg.Color); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, node.Line, err)
+			}
+		}
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("%s: no segments", path)
+	}
+	return segments, nil
+}
+
+var hexColorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// parseSegmentColor parses the color of a timer file segment, either one of the
+// --color names or a hex color like "#3FB950".
+func parseSegmentColor(s string) (barColor, error) {
+	if strings.HasPrefix(s, "#") {
+		if !hexColorRe.MatchString(s) {
+			return barColor{}, fmt.Errorf("invalid color %q, expected e.g. #3FB950", s)
+		}
+		return barColor{from: s}, nil
+	}
+	return progressColor(s)
+}
+
+var timerFile string
+
+var runCmd = &cobra.Command{
+	Use:          "run --file <timer.yaml>",
+	Short:        "Runs the segments of a YAML timer file",
+	Example:      "  - duration: 25m\n    name: work\n    exec_on_complete: notify-send 'Take a break'\n    color: green\n  - duration: 5m\n    name: break\n    color: \"#0550AE\"",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		segments, err := readTimerFile(timerFile)
+		if err != nil {
+			return err
+		}
+
+		durations := make([]string, len(segments))
+		names := make([]string, len(segments))
+		segmentExecs = make([]string, len(segments))
+		segmentColors = make([]barColor, len(segments))
+		named := false
+		for i, seg := range segments {
+			durations[i] = seg.duration.String()
+			if seg.delay {
+				durations[i] = "+" + durations[i]
+			}
+			names[i] = seg.Name
+			segmentExecs[i] = seg.ExecOnComplete
+			segmentColors[i] = seg.color
+			named = named || seg.Name != ""
+		}
+		if name == "" && named {
+			// The names are only joined for display, a name may contain
+			// commas itself.
+			name = strings.Join(names, ",")
+			segmentNames = names
+		}
+		separator = "," // the durations are joined below, not typed by the user
+		return rootCmd.RunE(cmd, []string{strings.Join(durations, ",")})
+	},
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// tmuxStatus is the data available to the --tmux-format template.
+type tmuxStatus struct {
+	Name      string
+	Remaining string
+	Elapsed   string
+	Percent   int
+}
+
+// tmuxOption is the user option toki sets on the --tmux-pane.
+const tmuxOption = "@toki_progress"
+
+// writeTmux returns a command setting the tmux option of the --tmux-pane to
+// the progress of the running segment. Failures are ignored so that a closed
+// pane does not stop the timer.
+func (m model) writeTmux() tea.Cmd {
+	if m.tmuxPane == "" {
+		return nil
+	}
+	total := m.durations[m.state]
+	var b strings.Builder
+	err := m.tmuxFormat.Execute(&b, tmuxStatus{
+		Name:      m.segmentName(),
+		Remaining: max(total-m.passed, 0).Round(time.Second).String(),
+		Elapsed:   m.passed.Round(time.Second).String(),
+		Percent:   int(min(float64(m.passed)/float64(total), 1) * 100),
+	})
+	if err != nil {
+		return nil
+	}
+	pane := m.tmuxPane
+	return func() tea.Msg {
+		_ = exec.Command("tmux", "set-option", "-t", pane, tmuxOption, b.String()).Run()
+		return nil
+	}
+}
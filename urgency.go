@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/v2/progress"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+)
+
+// urgencyConfig is the [urgency] section of the config file. Thresholds are
+// percentages of the running segment.
+type urgencyConfig struct {
+	Warning  float64 `toml:"warning"`
+	Critical float64 `toml:"critical"`
+}
+
+// barColor is a progress bar gradient, or a solid fill when to is empty.
+type barColor struct {
+	from, to string
+}
+
+// option returns the progress option applying c, with the gradient flipped
+// when reverse is set.
+func (c barColor) option(reverse bool) progress.Option {
+	if c.to == "" {
+		return progress.WithSolidFill(lipgloss.Color(c.from))
+	}
+	if reverse {
+		return progress.WithGradient(c.to, c.from)
+	}
+	return progress.WithGradient(c.from, c.to)
+}
+
+// urgencyBar is a progress bar whose colors shift toward yellow and then red
+// as the percentage crosses the warning and critical thresholds. With reverse
+// set it drains from full to empty instead of filling up. A renderer replaces
+// the gradient bar of progress.Model with another --progress-style.
+type urgencyBar struct {
+	progress.Model
+	renderer Renderer
+	colors   [3]barColor
+	warning  float64
+	critical float64
+	level    int
+	urgent   bool
+	reverse  bool
+}
+
+func newUrgencyBar(theme Theme, renderer Renderer, reverse, urgent bool, cfg urgencyConfig) urgencyBar {
+	return urgencyBar{
+		Model:    progress.New(theme.Progress.option(reverse)),
+		renderer: renderer,
+		colors:   [3]barColor{theme.Progress, theme.Warning, theme.Critical},
+		warning:  cfg.Warning / 100,
+		critical: cfg.Critical / 100,
+		urgent:   urgent,
+		reverse:  reverse,
+	}
+}
+
+// SetPercent sets the elapsed percentage of the bar, switching its colors
+// when a threshold is crossed.
+func (b *urgencyBar) SetPercent(p float64) tea.Cmd {
+	if b.urgent {
+		level := 0
+		switch {
+		case p >= b.critical:
+			level = 2
+		case p >= b.warning:
+			level = 1
+		}
+		if level != b.level {
+			b.colors[level].option(b.reverse)(&b.Model)
+			b.level = level
+		}
+	}
+	if b.reverse {
+		p = 1 - p
+	}
+	return b.Model.SetPercent(p)
+}
+
+// SetBaseColor replaces the colors used below the warning threshold.
+func (b *urgencyBar) SetBaseColor(c barColor) {
+	b.colors[0] = c
+	if b.level == 0 {
+		c.option(b.reverse)(&b.Model)
+	}
+}
+
+// View renders the bar with its renderer, if any, in the first color of the
+// current urgency level, followed by the percentage unless it is hidden.
+func (b urgencyBar) View() string {
+	if b.renderer == nil {
+		return b.Model.View()
+	}
+	percent := ""
+	if b.ShowPercentage {
+		percent = fmt.Sprintf(" %3.0f%%", b.Percent()*100)
+	}
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color(b.colors[b.level].from))
+	return style.Render(b.renderer.Render(b.Percent(), b.Width()-len(percent))) + percent
+}
+
+func (b urgencyBar) Update(msg tea.Msg) (urgencyBar, tea.Cmd) {
+	var cmd tea.Cmd
+	b.Model, cmd = b.Model.Update(msg)
+	return b, cmd
+}